@@ -0,0 +1,281 @@
+package segmentedSlice
+
+import "fmt"
+
+// NewFromSlice returns a new Slice with the specified segment length holding a copy of src,
+// the elements are copied a whole segment at a time, which is much faster than appending them one by one.
+func NewFromSlice(segLen int, src []interface{}, opts ...Option) *Slice {
+	ss := New(segLen, opts...)
+	ss.AppendSlice(src)
+	return ss
+}
+
+// AppendSlice appends vals to the slice, it grows the slice once and copies vals a whole segment at a time.
+// The values are converted according to the slice's ElemMode and codec.
+// If used on a sub-slice, it turns into an independent slice.
+func (ss *Slice) AppendSlice(vals []interface{}) {
+	ss.Grow(len(vals))
+	if ss.metrics != nil {
+		ss.metrics.Appended(len(vals))
+	}
+	ss.own(ss.len, ss.len+len(vals))
+	for len(vals) > 0 {
+		di, si := ss.index(ss.len)
+		seg := ss.data[di][si:]
+		n := copy(seg, vals)
+		ss.storeAll(seg[:n])
+		vals, ss.len = vals[n:], ss.len+n
+	}
+	ss.mods++
+}
+
+// ToSlice returns the elements of the slice as a plain []interface{}.
+func (ss *Slice) ToSlice() []interface{} {
+	return ss.AppendToSlice(make([]interface{}, 0, ss.len))
+}
+
+// AppendToSlice appends the elements of the slice to dst and returns the extended slice,
+// the elements are copied segment by segment.
+func (ss *Slice) AppendToSlice(dst []interface{}) []interface{} {
+	ss.forEachSeg(0, ss.len, func(_ int, seg []interface{}) (_ bool) {
+		n := len(dst)
+		dst = append(dst, seg...)
+		ss.loadAll(dst[n:])
+		return
+	})
+	return dst
+}
+
+// CopyTo copies the elements of the slice to dst and returns the number of copied elements,
+// which is the minimum of len(dst) and Len(), the same as the built-in copy.
+func (ss *Slice) CopyTo(dst []interface{}) int {
+	n := len(dst)
+	if n > ss.len {
+		n = ss.len
+	}
+
+	ss.forEachSeg(0, n, func(off int, seg []interface{}) (_ bool) {
+		copy(dst[off:], seg)
+		return
+	})
+	ss.loadAll(dst[:n])
+	return n
+}
+
+// GetRange returns a copy of the elements in [start, end), it panics if the range is invalid.
+func (ss *Slice) GetRange(start, end int) []interface{} {
+	ss.checkRange(start, end)
+	out := make([]interface{}, end-start)
+	ss.forEachSeg(start, end, func(off int, seg []interface{}) (_ bool) {
+		copy(out[off-start:], seg)
+		return
+	})
+	ss.loadAll(out)
+	return out
+}
+
+// SetRange sets the elements starting at start to vals, it panics if start+len(vals) > Len().
+// The values are converted according to the slice's ElemMode and codec.
+func (ss *Slice) SetRange(start int, vals []interface{}) {
+	ss.checkRange(start, start+len(vals))
+	ss.own(start, start+len(vals))
+	ss.forEachSeg(start, start+len(vals), func(off int, seg []interface{}) (_ bool) {
+		copy(seg, vals[off-start:])
+		ss.storeAll(seg)
+		return
+	})
+}
+
+// CopyWithin copies the elements in [srcStart, srcEnd) to the position dst of the same slice, the ranges may overlap.
+// Like JavaScript's copyWithin, the slice doesn't grow, only the elements that fit before the end are copied.
+// It panics if the source range is invalid or dst isn't in [0, Len()].
+func (ss *Slice) CopyWithin(dst, srcStart, srcEnd int) {
+	ss.checkRange(srcStart, srcEnd)
+	if dst < 0 || dst > ss.len {
+		panic(fmt.Sprintf("index out of range [%d] with length %d", dst, ss.len))
+	}
+
+	n := srcEnd - srcStart
+	if n > ss.len-dst {
+		n = ss.len - dst
+	}
+	ss.move(dst, srcStart, n)
+}
+
+// Fill sets every element of the slice to v.
+func (ss *Slice) Fill(v interface{}) { ss.FillRange(0, ss.len, v) }
+
+// FillRange sets the elements in [start, end) to v, it panics if the range is invalid.
+// With ElemByPointer, every element gets its own copy of v.
+func (ss *Slice) FillRange(start, end int, v interface{}) {
+	ss.checkRange(start, end)
+	ss.own(start, end)
+
+	sv := ss.store(v)
+	ss.forEachSeg(start, end, func(_ int, seg []interface{}) (_ bool) {
+		for i := range seg {
+			seg[i] = sv
+			if ss.mode == ElemByPointer {
+				sv = ss.store(v)
+			}
+		}
+		return
+	})
+}
+
+// Resize changes the length of the slice to exactly n, new elements are set to fill
+// and removed elements are set to nil so they can be garbage collected.
+// If used on a sub-slice, it turns into an independent slice.
+func (ss *Slice) Resize(n int, fill interface{}) {
+	if n < 0 {
+		panic("n must be >= 0")
+	}
+
+	if n <= ss.len {
+		ss.Truncate(n, false)
+		return
+	}
+
+	ss.Grow(0)
+	old := ss.len
+	ss.Grow(n - old)
+	ss.len = n
+	ss.mods++
+	ss.FillRange(old, n, fill)
+}
+
+// Reset empties the slice, the elements are set to nil but the segments are kept so the capacity can be reused.
+// Sub-slices and slices sharing segments with a snapshot drop their segments instead,
+// and slices with a segment pool return them to the pool (see WithSegmentPool).
+func (ss *Slice) Reset() {
+	ss.checkFrozen()
+	if ss.view || ss.baseIdx != 0 {
+		ss.data, ss.cap, ss.baseIdx, ss.shared, ss.view = nil, 0, 0, nil, false
+	} else if ss.pool != nil {
+		ss.releaseSegments(0)
+		ss.shared = nil
+	} else if ss.shared != nil {
+		ss.data, ss.cap, ss.shared = nil, 0, nil
+	} else {
+		ss.clearRange(0, ss.len)
+	}
+	ss.len = 0
+	ss.mods++
+}
+
+// Reserve allocates enough segments for the slice to hold n elements in total without growing again,
+// it doesn't do anything if the capacity is already large enough.
+// Unlike Grow, it never copies a sub-slice, it doesn't do anything on sub-slices since they are copied on their first append.
+func (ss *Slice) Reserve(n int) {
+	ss.checkFrozen()
+	if ss.view || ss.baseIdx != 0 || n <= ss.cap {
+		return
+	}
+	ss.Grow(n - ss.len)
+}
+
+// Truncate shortens the slice to n elements, the removed elements are set to nil so they can be garbage collected.
+// If release is true, the segments that are left empty are dropped as well, reducing the capacity.
+// It panics if n isn't in [0, Len()]. If used on a sub-slice, it turns into an independent slice.
+func (ss *Slice) Truncate(n int, release bool) {
+	if n < 0 || n > ss.len {
+		panic(fmt.Sprintf("truncate length out of range [%d] with length %d", n, ss.len))
+	}
+
+	ss.Grow(0)
+	ss.clearRange(n, ss.len)
+	ss.len = n
+	ss.mods++
+
+	if release {
+		ss.releaseSegments(ss.segmentsCovering(n))
+	}
+}
+
+// Compact releases the segments past Len() and shrinks the segment directory to fit,
+// so the memory left after Pop, Truncate or Filter can be garbage collected (or reused by the pool).
+// It doesn't do anything on sub-slices.
+func (ss *Slice) Compact() {
+	ss.checkFrozen()
+	if ss.view || ss.baseIdx != 0 {
+		return
+	}
+
+	ss.releaseSegments(ss.segmentsCovering(ss.len))
+	if cap(ss.data) > len(ss.data) {
+		ss.data = append([][]interface{}(nil), ss.data...)
+		if ss.shared != nil {
+			ss.shared = append([]bool(nil), ss.shared...)
+		}
+	}
+}
+
+// TrimFront drops the references to the whole segments before the start of a sub-slice, so a queue consumer
+// that keeps reslicing with Slice(1, Len()) doesn't keep the consumed elements alive.
+// The trimmed slice stops sharing writes with its parent, its segments are copied on the first write (see Snapshot).
+// It doesn't do anything if there are no whole segments before the start of the slice.
+// With geometric segments (see WithGeometricSegments) the elements are copied instead.
+func (ss *Slice) TrimFront() {
+	first, _ := ss.index(ss.baseIdx)
+	if first == 0 {
+		return
+	}
+
+	if ss.geo != nil { // the segment lengths depend on the absolute index, so they can't be rebased
+		frozen, mods := ss.frozen, ss.mods
+		*ss = *ss.Copy()
+		ss.frozen, ss.mods = frozen, mods
+		return
+	}
+
+	end := (ss.baseIdx + ss.len + ss.segLen) >> ss.shift
+	if ss.len == 0 {
+		end = first
+	}
+
+	ss.data = append([][]interface{}(nil), ss.data[first:end]...)
+	ss.shared = make([]bool, len(ss.data))
+	for i := range ss.shared {
+		ss.shared[i] = true
+	}
+	ss.baseIdx &= ss.segLen
+	ss.cap = len(ss.data) * (ss.segLen + 1)
+	ss.view = false // the directory is ss's own now
+}
+
+// releaseSegments drops all the segments past the first keep ones, returning them to the pool if the slice has one.
+func (ss *Slice) releaseSegments(keep int) {
+	if keep >= len(ss.data) {
+		return
+	}
+
+	for di := keep; di < len(ss.data); di++ {
+		if ss.pool != nil && !ss.view && ss.data[di] != nil && !ss.isShared(di) {
+			ss.pool.put(ss.data[di])
+		}
+		ss.cap -= ss.segSize(di)
+	}
+
+	// the directory may be shared with a parent or a snapshot, so it is copied rather than modified in place
+	ss.data = append([][]interface{}(nil), ss.data[:keep]...)
+	if ss.shared != nil {
+		ss.shared = append([]bool(nil), ss.shared[:keep]...)
+	}
+}
+
+// loadAll decodes the stored values in vals in place and drops their deadline tags, see SetCodec.
+func (ss *Slice) loadAll(vals []interface{}) {
+	for i, v := range vals {
+		vals[i] = ss.load(v)
+	}
+}
+
+// storeAll converts the values in vals in place to the values stored in the slice, see ElemMode and SetCodec.
+func (ss *Slice) storeAll(vals []interface{}) {
+	if ss.mode == ElemAsIs && ss.enc == nil {
+		return
+	}
+	for i, v := range vals {
+		vals[i] = ss.store(v)
+	}
+}
@@ -0,0 +1,34 @@
+package segmentedSlice
+
+// SetCodec sets hooks that transform the elements on their way in and out of the slice,
+// e.g. to compress, encrypt or normalize the stored values. Either hook may be nil.
+// enc is called on every value written by Append, Set and the other mutating methods,
+// dec is called on every stored value read by Get, ForEach, iterators and the other reading methods.
+// GetUnchecked, SetUnchecked and the segments returned by low level helpers hold the stored (encoded) values,
+// including the deadline tags set by AppendWithDeadline and SetDeadline.
+// Example:
+// 	ss.SetCodec(
+// 		func(v interface{}) interface{} { return compress(v.([]byte)) },
+// 		func(v interface{}) interface{} { return decompress(v.([]byte)) },
+// 	)
+func (ss *Slice) SetCodec(enc, dec func(v interface{}) interface{}) {
+	ss.enc, ss.dec = enc, dec
+}
+
+// store converts v to the value stored in the slice.
+func (ss *Slice) store(v interface{}) interface{} {
+	v = ss.elem(v)
+	if ss.enc != nil {
+		return ss.enc(v)
+	}
+	return v
+}
+
+// load converts a stored value to the value returned to the caller.
+func (ss *Slice) load(v interface{}) interface{} {
+	v = ss.untag(v)
+	if ss.dec != nil {
+		return ss.dec(v)
+	}
+	return v
+}
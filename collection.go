@@ -0,0 +1,131 @@
+package segmentedSlice
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrBudgetExceeded is returned by Collection.Append when growing a slice would go over the collection's budget.
+var ErrBudgetExceeded = errors.New("collection budget exceeded")
+
+// Collection manages many named slices (per tenant, topic, etc) that share a segment pool and a memory budget.
+// All the methods are safe for concurrent use.
+type Collection struct {
+	mu     sync.Mutex
+	segLen int
+	budget int
+	used   int
+	pool   SegmentPool
+	slices map[string]*Slice
+}
+
+// CollectionStats holds aggregate stats of all the slices in a Collection.
+type CollectionStats struct {
+	Slices   int
+	Len      int
+	Cap      int
+	Segments int
+	Budget   int
+}
+
+// NewCollection returns a new Collection where every slice has the specified segment length.
+// budget is the max total capacity, in elements, of all the slices; 0 means unlimited.
+func NewCollection(segLen, budget int) *Collection {
+	if !isPowerOfTwo(segLen) {
+		panic("segLen is not power of two")
+	}
+
+	return &Collection{
+		segLen: segLen,
+		budget: budget,
+		slices: make(map[string]*Slice),
+	}
+}
+
+// Append appends vals to the named slice, creating it if needed.
+// It returns ErrBudgetExceeded without appending anything if the needed segments would go over the budget.
+func (c *Collection) Append(name string, vals ...interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ss := c.slice(name)
+	if need := ss.segmentsFor(len(vals)) * c.segLen; c.budget > 0 && c.used+need > c.budget {
+		return ErrBudgetExceeded
+	}
+
+	before := ss.cap
+	ss.Append(vals...)
+	c.used += ss.cap - before
+	return nil
+}
+
+// Do calls fn with the named slice, creating it if needed, while holding the collection's lock.
+// The slice must not be retained after fn returns. Any capacity change made by fn is accounted for after it returns,
+// but the budget isn't enforced on it.
+func (c *Collection) Do(name string, fn func(ss *Slice)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ss := c.slice(name)
+	before := ss.cap
+	fn(ss)
+	c.used += ss.cap - before
+}
+
+// Drop removes the named slice and returns its segments to the shared pool.
+func (c *Collection) Drop(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ss, ok := c.slices[name]
+	if !ok {
+		return
+	}
+
+	delete(c.slices, name)
+	c.used -= ss.cap
+	for di, seg := range ss.data {
+		if seg != nil && !ss.isShared(di) {
+			c.pool.put(seg)
+		}
+	}
+	*ss = Slice{}
+}
+
+// Names returns the sorted names of all the slices in the collection.
+func (c *Collection) Names() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	names := make([]string, 0, len(c.slices))
+	for name := range c.slices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stats returns aggregate stats of all the slices in the collection.
+func (c *Collection) Stats() (st CollectionStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	st.Slices, st.Budget = len(c.slices), c.budget
+	for _, ss := range c.slices {
+		st.Len += ss.len
+		st.Cap += ss.cap
+		st.Segments += len(ss.data)
+	}
+	return
+}
+
+func (c *Collection) slice(name string) *Slice {
+	ss := c.slices[name]
+	if ss == nil {
+		ss = New(c.segLen)
+		ss.pool = &c.pool
+		c.slices[name] = ss
+	}
+	return ss
+}
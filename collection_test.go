@@ -0,0 +1,37 @@
+package segmentedSlice
+
+import "testing"
+
+func TestCollection(t *testing.T) {
+	c := NewCollection(4, 16)
+
+	if err := c.Append("a", 1, 2, 3, 4, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Append("b", 1, 2, 3, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Append("b", 5, 6, 7, 8, 9); err != ErrBudgetExceeded {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+
+	st := c.Stats()
+	if st.Slices != 2 || st.Len != 9 || st.Cap != 16 || st.Segments != 4 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+
+	c.Drop("a")
+	if err := c.Append("b", 5, 6, 7, 8, 9); err != nil {
+		t.Fatal(err)
+	}
+
+	if names := c.Names(); len(names) != 1 || names[0] != "b" {
+		t.Fatalf("unexpected names: %v", names)
+	}
+
+	c.Do("b", func(ss *Slice) {
+		if ss.Len() != 9 || ss.Get(8).(int) != 9 || ss.Cap() != 12 {
+			t.Fatalf("unexpected slice: %v", ss)
+		}
+	})
+}
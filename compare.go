@@ -0,0 +1,72 @@
+package segmentedSlice
+
+import (
+	"hash"
+	"hash/fnv"
+)
+
+// DiffIndices returns the indices of the elements that differ between ss and other according to eq.
+// If the slices have different lengths, the indices past the end of the shorter one are all considered different.
+func (ss *Slice) DiffIndices(other *Slice, eq func(a, b interface{}) bool) (idxs []int) {
+	n, max := ss.len, other.len
+	if n > max {
+		n, max = max, n
+	}
+
+	ss.forEachSegPair(other, n, func(off int, a, b []interface{}) (_ bool) {
+		for i := range a {
+			if !eq(ss.load(a[i]), other.load(b[i])) {
+				idxs = append(idxs, off+i)
+			}
+		}
+		return
+	})
+
+	for i := n; i < max; i++ {
+		idxs = append(idxs, i)
+	}
+
+	return
+}
+
+// Equal returns true if ss and other have the same length and eq returns true for every pair of elements.
+// The comparison stops at the first difference.
+func (ss *Slice) Equal(other *Slice, eq func(a, b interface{}) bool) bool {
+	if ss.len != other.len {
+		return false
+	}
+
+	return !ss.forEachSegPair(other, ss.len, func(_ int, a, b []interface{}) bool {
+		for i := range a {
+			if !eq(ss.load(a[i]), other.load(b[i])) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Hash returns a 64-bit FNV-1a hash of the slice's content, h is called for every element in order
+// and must write a stable encoding of v to the hash.
+// Since the elements are written back to back, h should write a length or a delimiter for variable sized values,
+// otherwise ["ab", "c"] and ["a", "bc"] hash the same.
+func (ss *Slice) Hash(h func(w hash.Hash64, v interface{})) uint64 {
+	w := fnv.New64a()
+	ss.forEachSeg(0, ss.len, func(_ int, seg []interface{}) (_ bool) {
+		for _, v := range seg {
+			h(w, ss.load(v))
+		}
+		return
+	})
+	return w.Sum64()
+}
+
+// forEachSegPair calls fn with the aligned parts of the segments of ss and other that cover [0, n),
+// a and b always have the same length.
+func (ss *Slice) forEachSegPair(other *Slice, n int, fn func(off int, a, b []interface{}) (breakNow bool)) bool {
+	return ss.forEachSeg(0, n, func(off int, a []interface{}) bool {
+		return other.forEachSeg(off, off+len(a), func(boff int, b []interface{}) bool {
+			return fn(boff, a[boff-off:boff-off+len(b)], b)
+		})
+	})
+}
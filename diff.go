@@ -0,0 +1,186 @@
+package segmentedSlice
+
+// EditOp is the kind of an Edit.
+type EditOp uint8
+
+const (
+	// EditEqual is a run of elements that are in both slices.
+	EditEqual EditOp = iota
+	// EditDelete is a run of elements of the original slice that aren't in the other slice.
+	EditDelete
+	// EditInsert is a run of elements of the other slice that aren't in the original slice.
+	EditInsert
+)
+
+func (op EditOp) String() string {
+	switch op {
+	case EditEqual:
+		return "="
+	case EditDelete:
+		return "-"
+	case EditInsert:
+		return "+"
+	}
+	return "?"
+}
+
+// Edit is a run of Len elements with the same EditOp.
+// Index is the position in the original slice and OtherIndex the position in the other slice,
+// an EditInsert inserts other[OtherIndex:OtherIndex+Len] before ss[Index],
+// an EditDelete deletes ss[Index:Index+Len], which would be before other[OtherIndex].
+type Edit struct {
+	Op                     EditOp
+	Index, OtherIndex, Len int
+}
+
+// Diff returns the shortest list of edits that turns ss into other, using eq to compare the elements.
+// It uses Myers' algorithm after trimming the common prefix and suffix, it takes O((N+M)D) time and O(N+M+D^2) extra memory
+// (the frontier of all the diagonals plus the trace of the diagonals reached by each round), where N and M are the lengths
+// left after trimming and D is the number of inserted and deleted elements, so it is fast for large slices with few differences.
+func (ss *Slice) Diff(other *Slice, eq func(a, b interface{}) bool) []Edit {
+	var (
+		n, m   = ss.len, other.len
+		prefix int
+		suffix int
+	)
+
+	for prefix < n && prefix < m && eq(ss.Get(prefix), other.Get(prefix)) {
+		prefix++
+	}
+	for suffix < n-prefix && suffix < m-prefix && eq(ss.Get(n-1-suffix), other.Get(m-1-suffix)) {
+		suffix++
+	}
+
+	d := &differ{
+		a:   ss.Slice(prefix, n-suffix),
+		b:   other.Slice(prefix, m-suffix),
+		eq:  eq,
+		off: prefix,
+	}
+
+	if suffix > 0 {
+		d.addRun(EditEqual, n-suffix, m-suffix, suffix)
+	}
+	d.run()
+	if prefix > 0 {
+		d.addRun(EditEqual, 0, 0, prefix)
+	}
+
+	edits := d.edits
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// differ holds the state of a Myers diff, the edits are collected in reverse order.
+type differ struct {
+	a, b  *Slice
+	eq    func(a, b interface{}) bool
+	off   int
+	edits []Edit
+}
+
+func (d *differ) run() {
+	n, m := d.a.len, d.b.len
+	if n == 0 && m == 0 {
+		return
+	}
+
+	var (
+		max   = n + m
+		v     = make([]int, 2*max+2)
+		trace [][]int
+	)
+
+	// v[max+1+k] is the furthest x reached on diagonal k, trace[d] holds v[-d:d+1] after round d.
+	at := func(k int) *int { return &v[max+1+k] }
+	for dd := 0; dd <= max; dd++ {
+		for k := -dd; k <= dd; k += 2 {
+			var x int
+			if k == -dd || (k != dd && *at(k - 1) < *at(k + 1)) {
+				x = *at(k + 1)
+			} else {
+				x = *at(k - 1) + 1
+			}
+
+			y := x - k
+			for x < n && y < m && d.eq(d.a.Get(x), d.b.Get(y)) {
+				x, y = x+1, y+1
+			}
+			*at(k) = x
+
+			if x >= n && y >= m {
+				trace = append(trace, append([]int(nil), v[max+1-dd:max+2+dd]...))
+				d.backtrack(trace, n, m)
+				return
+			}
+		}
+		trace = append(trace, append([]int(nil), v[max+1-dd:max+2+dd]...))
+	}
+}
+
+func (d *differ) backtrack(trace [][]int, x, y int) {
+	for dd := len(trace) - 1; dd > 0; dd-- {
+		prev := trace[dd-1]
+		at := func(k int) int { return prev[k+dd-1] }
+
+		k := x - y
+		var pk int
+		if k == -dd || (k != dd && at(k-1) < at(k+1)) {
+			pk = k + 1
+		} else {
+			pk = k - 1
+		}
+
+		px := at(pk)
+		py := px - pk
+
+		sx := px // start of the snake
+		if pk == k-1 {
+			sx++
+		}
+		for x > sx {
+			x, y = x-1, y-1
+			d.add(EditEqual, x, y)
+		}
+
+		if pk == k+1 {
+			d.add(EditInsert, x, py)
+		} else {
+			d.add(EditDelete, px, y)
+		}
+		x, y = px, py
+	}
+
+	for x > 0 {
+		x, y = x-1, y-1
+		d.add(EditEqual, x, y)
+	}
+}
+
+// add adds a single element edit at the positions i and j relative to the diffed ranges.
+func (d *differ) add(op EditOp, i, j int) { d.addRun(op, i+d.off, j+d.off, 1) }
+
+// addRun adds an edit of n elements, extending the previous edit if it has the same op and directly follows it.
+func (d *differ) addRun(op EditOp, i, j, n int) {
+	if l := len(d.edits); l > 0 {
+		if last := &d.edits[l-1]; last.Op == op {
+			var adjacent bool
+			switch op {
+			case EditEqual:
+				adjacent = last.Index == i+n && last.OtherIndex == j+n
+			case EditDelete:
+				adjacent = last.Index == i+n && last.OtherIndex == j
+			case EditInsert:
+				adjacent = last.Index == i && last.OtherIndex == j+n
+			}
+			if adjacent {
+				last.Index, last.OtherIndex = i, j
+				last.Len += n
+				return
+			}
+		}
+	}
+	d.edits = append(d.edits, Edit{Op: op, Index: i, OtherIndex: j, Len: n})
+}
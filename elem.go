@@ -0,0 +1,84 @@
+package segmentedSlice
+
+import "reflect"
+
+// ElemMode controls whether the elements of a Slice are stored as values or pointers.
+type ElemMode uint8
+
+const (
+	// ElemAsIs stores the elements exactly as they are passed, it is the default.
+	ElemAsIs ElemMode = iota
+	// ElemByValue stores the value pointed to rather than the pointer, Append(&T{}) stores a T.
+	// JSON unmarshal stores T even if the unmarshal type is set to *T.
+	ElemByValue
+	// ElemByPointer stores a pointer to a copy of non-pointer elements, Append(T{}) stores a *T.
+	// JSON unmarshal stores *T even if the unmarshal type is set to T.
+	ElemByPointer
+)
+
+func (m ElemMode) String() string {
+	switch m {
+	case ElemAsIs:
+		return "AsIs"
+	case ElemByValue:
+		return "ByValue"
+	case ElemByPointer:
+		return "ByPointer"
+	}
+	return "ElemMode(?)"
+}
+
+// WithElemMode sets how the elements are stored, see ElemMode.
+// Example:
+// 	ss := New(128, WithElemMode(ElemByPointer))
+// 	ss.SetUnmarshalType(DataStruct{}) // elements are *DataStruct
+func WithElemMode(m ElemMode) Option {
+	return func(ss *Slice) { ss.mode = m }
+}
+
+// ElemMode returns how the elements are stored.
+func (ss *Slice) ElemMode() ElemMode { return ss.mode }
+
+// DeepCopy is like Copy, but elements that are non-nil pointers are replaced with pointers to copies of their values,
+// so the returned slice doesn't share any pointed-to data with ss.
+// The copies are shallow, maps, slices and pointers inside the values are still shared.
+func (ss *Slice) DeepCopy() *Slice {
+	nss := ss.Copy()
+	if ss.mode == ElemByValue {
+		return nss
+	}
+
+	nss.forEachSeg(0, nss.len, func(_ int, seg []interface{}) (_ bool) {
+		for i, v := range seg {
+			if rv := reflect.ValueOf(ss.untag(v)); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+				cp := reflect.New(rv.Type().Elem())
+				cp.Elem().Set(rv.Elem())
+				seg[i] = ss.retag(v, cp.Interface())
+			}
+		}
+		return
+	})
+	return nss
+}
+
+// elem converts v according to the slice's ElemMode.
+func (ss *Slice) elem(v interface{}) interface{} {
+	if ss.mode == ElemAsIs || v == nil {
+		return v
+	}
+
+	rv := reflect.ValueOf(v)
+	isPtr := rv.Kind() == reflect.Ptr
+	switch {
+	case ss.mode == ElemByValue && isPtr:
+		if rv.IsNil() {
+			return nil
+		}
+		return rv.Elem().Interface()
+	case ss.mode == ElemByPointer && !isPtr:
+		p := reflect.New(rv.Type())
+		p.Elem().Set(rv)
+		return p.Interface()
+	}
+	return v
+}
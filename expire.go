@@ -0,0 +1,87 @@
+package segmentedSlice
+
+import "time"
+
+// Deadliner is implemented by elements that expire, see ExpireBefore.
+type Deadliner interface {
+	// Deadline returns the time the element expires at, the zero time means it never expires.
+	Deadline() time.Time
+}
+
+// deadlined is the stored form of an element with a deadline set by AppendWithDeadline or SetDeadline.
+// The tag lives in the element's own slot, so it moves with the element when others are inserted or removed
+// and on Compact, Sort, Copy, Split and Rechunk without any extra bookkeeping.
+type deadlined struct {
+	v  interface{}
+	dl time.Time
+}
+
+// AppendWithDeadline appends v and tags it with the deadline dl, the zero time means it never expires.
+// The tag is kept by the methods that move elements around and dropped when the element is replaced by Set,
+// Transform keeps it. Elements that implement Deadliner don't need a tag, see ExpireBefore.
+func (ss *Slice) AppendWithDeadline(v interface{}, dl time.Time) {
+	ss.Append(v)
+	ss.SetDeadline(ss.len-1, dl)
+}
+
+// SetDeadline sets the deadline of the element at index i, the zero time removes it.
+// It panics if i is out of range.
+func (ss *Slice) SetDeadline(i int, dl time.Time) {
+	ss.checkIndex(i)
+	ss.own(i, i+1)
+	p := ss.ptrAt(ss.baseIdx + i)
+	v := ss.untag(*p)
+	if !dl.IsZero() {
+		v = deadlined{v, dl}
+	}
+	*p = v
+}
+
+// Deadline returns the deadline of the element at index i set by AppendWithDeadline or SetDeadline,
+// or the one returned by the element itself if it implements Deadliner.
+// ok is false if the element has no deadline. It panics if i is out of range.
+func (ss *Slice) Deadline(i int) (dl time.Time, ok bool) {
+	ss.checkIndex(i)
+	dl = ss.deadline(ss.GetUnchecked(i))
+	return dl, !dl.IsZero()
+}
+
+// ExpireBefore removes all the elements with a deadline before t in one pass and returns the number of removed
+// elements, the order of the remaining elements is kept.
+// The deadline of an element is the one set by AppendWithDeadline or SetDeadline, or the one returned by the
+// element itself if it implements Deadliner. Elements without a deadline never expire.
+// If used on a sub-slice, it turns into an independent slice.
+func (ss *Slice) ExpireBefore(t time.Time) int {
+	return ss.retainStored(func(v interface{}) bool {
+		dl := ss.deadline(v)
+		return dl.IsZero() || !dl.Before(t)
+	})
+}
+
+// deadline returns the deadline of the stored value v or the zero time.
+func (ss *Slice) deadline(v interface{}) time.Time {
+	if d, ok := v.(deadlined); ok {
+		return d.dl
+	}
+	if d, ok := ss.load(v).(Deadliner); ok {
+		return d.Deadline()
+	}
+	return time.Time{}
+}
+
+// untag returns the stored value v without its deadline tag.
+func (ss *Slice) untag(v interface{}) interface{} {
+	if d, ok := v.(deadlined); ok {
+		return d.v
+	}
+	return v
+}
+
+// retag gives nv the deadline tag of the stored value old, if it has one.
+func (ss *Slice) retag(old, nv interface{}) interface{} {
+	if d, ok := old.(deadlined); ok {
+		d.v = nv
+		return d
+	}
+	return nv
+}
@@ -0,0 +1,270 @@
+package segmentedSlice
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Scan returns a new Slice holding the running accumulation of fn over the slice,
+// where the i-th element is fn(...fn(fn(initial, ss[0]), ss[1])..., ss[i]).
+// Example (prefix sums):
+// 	sums := ss.Scan(0, func(acc, v interface{}) interface{} { return acc.(int) + v.(int) })
+func (ss *Slice) Scan(initial interface{}, fn func(acc, v interface{}) interface{}) *Slice {
+	nss := New(ss.segLen + 1)
+	nss.Grow(ss.len)
+	acc := initial
+	ss.ForEach(func(_ int, v interface{}) (_ bool) {
+		acc = fn(acc, v)
+		nss.Append(acc)
+		return
+	})
+	return nss
+}
+
+// Reduce folds the slice into a single value, calling fn with the accumulated value and each element in order.
+// Example:
+// 	sum := ss.Reduce(0, func(acc, v interface{}) interface{} { return acc.(int) + v.(int) }).(int)
+func (ss *Slice) Reduce(init interface{}, fn func(acc, v interface{}) interface{}) interface{} {
+	acc := init
+	ss.ForEach(func(_ int, v interface{}) (_ bool) {
+		acc = fn(acc, v)
+		return
+	})
+	return acc
+}
+
+// Any returns true if fn returns true for at least one element, it stops at the first match.
+func (ss *Slice) Any(fn func(v interface{}) bool) bool {
+	return ss.ForEachAt(0, func(_ int, v interface{}) bool { return fn(v) })
+}
+
+// All returns true if fn returns true for every element (or the slice is empty), it stops at the first mismatch.
+func (ss *Slice) All(fn func(v interface{}) bool) bool {
+	return !ss.ForEachAt(0, func(_ int, v interface{}) bool { return !fn(v) })
+}
+
+// None returns true if fn returns false for every element (or the slice is empty), it stops at the first match.
+func (ss *Slice) None(fn func(v interface{}) bool) bool { return !ss.Any(fn) }
+
+// Find returns the first element fn returns true for.
+func (ss *Slice) Find(fn func(v interface{}) bool) (v interface{}, ok bool) {
+	ok = ss.ForEach(func(_ int, sv interface{}) bool {
+		if fn(sv) {
+			v = sv
+			return true
+		}
+		return false
+	})
+	return
+}
+
+// FindIndex returns the index of the first element fn returns true for, or -1.
+func (ss *Slice) FindIndex(fn func(v interface{}) bool) (idx int) {
+	idx = -1
+	ss.ForEach(func(i int, v interface{}) bool {
+		if fn(v) {
+			idx = i
+			return true
+		}
+		return false
+	})
+	return
+}
+
+// CountFunc returns the number of elements fn returns true for.
+func (ss *Slice) CountFunc(fn func(v interface{}) bool) (n int) {
+	ss.ForEach(func(_ int, v interface{}) (_ bool) {
+		if fn(v) {
+			n++
+		}
+		return
+	})
+	return
+}
+
+// Count returns the number of elements equal to v according to eq.
+func (ss *Slice) Count(v interface{}, eq func(a, b interface{}) bool) int {
+	return ss.CountFunc(func(sv interface{}) bool { return eq(sv, v) })
+}
+
+// Summary computes basic statistics over the values returned by valFn in a single pass,
+// stddev is the population standard deviation. All the values are 0 for an empty slice.
+func (ss *Slice) Summary(valFn func(v interface{}) float64) (count int, min, max, mean, stddev float64) {
+	var m2 float64
+	ss.ForEach(func(_ int, v interface{}) (_ bool) {
+		x := valFn(v)
+		if count++; count == 1 {
+			min, max = x, x
+		} else if x < min {
+			min = x
+		} else if x > max {
+			max = x
+		}
+
+		// Welford's online algorithm
+		d := x - mean
+		mean += d / float64(count)
+		m2 += d * (x - mean)
+		return
+	})
+
+	if count > 0 {
+		stddev = math.Sqrt(m2 / float64(count))
+	}
+	return
+}
+
+// Transform replaces every element in the slice with fn(element).
+// It works directly on the segments, so it is much faster than a Get/Set loop.
+func (ss *Slice) Transform(fn func(v interface{}) interface{}) {
+	ss.own(0, ss.len)
+	ss.forEachSeg(0, ss.len, func(_ int, seg []interface{}) (_ bool) {
+		for i, v := range seg {
+			seg[i] = ss.retag(v, ss.store(fn(ss.load(v))))
+		}
+		return
+	})
+}
+
+// Filter returns a new Slice with the elements fn returns true for,
+// the new slice has the same segment length and lessFn as ss.
+func (ss *Slice) Filter(fn func(v interface{}) bool) *Slice {
+	nss := ss.newEmpty()
+	ss.ForEach(func(_ int, v interface{}) (_ bool) {
+		if fn(v) {
+			nss.Append(v)
+		}
+		return
+	})
+	return nss
+}
+
+// FilterInPlace removes the elements fn returns false for and returns the number of removed elements.
+// If used on a sub-slice, it turns into an independent slice.
+func (ss *Slice) FilterInPlace(fn func(v interface{}) bool) int {
+	return ss.retain(fn)
+}
+
+// Dedup removes consecutive duplicate elements according to eq, keeping the first of each run,
+// and returns the number of removed elements. On sorted data, it removes all the duplicates.
+// If used on a sub-slice, it turns into an independent slice.
+func (ss *Slice) Dedup(eq func(a, b interface{}) bool) int {
+	var (
+		prev  interface{}
+		first = true
+	)
+	return ss.retain(func(v interface{}) bool {
+		if !first && eq(prev, v) {
+			return false
+		}
+		first, prev = false, v
+		return true
+	})
+}
+
+// Unique removes all the duplicate elements, where elements with the same hash are considered equal,
+// keeping the first occurrence of each, and returns the number of removed elements.
+// If used on a sub-slice, it turns into an independent slice.
+func (ss *Slice) Unique(hash func(v interface{}) string) int {
+	seen := make(map[string]struct{})
+	return ss.retain(func(v interface{}) bool {
+		h := hash(v)
+		if _, ok := seen[h]; ok {
+			return false
+		}
+		seen[h] = struct{}{}
+		return true
+	})
+}
+
+// GroupBy splits the elements into new slices by the value returned by key, the elements keep their relative order.
+// The new slices have the same segment length and lessFn as ss.
+func (ss *Slice) GroupBy(key func(v interface{}) interface{}) map[interface{}]*Slice {
+	groups := make(map[interface{}]*Slice)
+	ss.ForEach(func(_ int, v interface{}) (_ bool) {
+		k := key(v)
+		g := groups[k]
+		if g == nil {
+			g = ss.newEmpty()
+			groups[k] = g
+		}
+		g.Append(v)
+		return
+	})
+	return groups
+}
+
+// Map returns a new Slice with the result of fn for every element.
+func (ss *Slice) Map(fn func(v interface{}) interface{}) *Slice {
+	nss := New(ss.segLen + 1)
+	nss.Grow(ss.len)
+	nss.len = ss.len
+	ss.forEachSegPair(nss, ss.len, func(_ int, src, dst []interface{}) (_ bool) {
+		for i, v := range src {
+			dst[i] = fn(ss.load(v))
+		}
+		return
+	})
+	return nss
+}
+
+// MapInPlace is an alias for Transform(fn).
+func (ss *Slice) MapInPlace(fn func(v interface{}) interface{}) { ss.Transform(fn) }
+
+// MapParallel returns a new Slice with the result of fn for every element, using the specified number of workers.
+// Each worker fills whole destination segments, so the order is kept without any locking.
+// If workers < 1, runtime.GOMAXPROCS(0) is used. fn must be safe for concurrent use.
+func (ss *Slice) MapParallel(workers int, fn func(v interface{}) interface{}) *Slice {
+	nss := New(ss.segLen + 1)
+	nss.Grow(ss.len)
+	nss.len = ss.len
+
+	if ss.len == 0 {
+		return nss
+	}
+
+	segLen := nss.segLen + 1
+	nsegs := (ss.len + segLen - 1) / segLen
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > nsegs {
+		workers = nsegs
+	}
+
+	var (
+		wg   sync.WaitGroup
+		next int64 = -1
+	)
+
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				k := int(atomic.AddInt64(&next, 1))
+				if k >= nsegs {
+					return
+				}
+
+				start, end := k*segLen, (k+1)*segLen
+				if end > ss.len {
+					end = ss.len
+				}
+
+				dst := nss.data[k]
+				ss.forEachSeg(start, end, func(off int, seg []interface{}) (_ bool) {
+					for i, v := range seg {
+						dst[off-start+i] = fn(ss.load(v))
+					}
+					return
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	return nss
+}
@@ -0,0 +1,118 @@
+//go:build ignore
+// +build ignore
+
+// gen_unboxed generates the typed methods of the unboxed slices, run it with go generate.
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"text/template"
+)
+
+var types = []struct{ Name, Type string }{
+	{"IntSlice", "int"},
+	{"Float64Slice", "float64"},
+	{"StringSlice", "string"},
+	{"ByteSlice", "byte"},
+}
+
+var tmpl = template.Must(template.New("").Parse(`// Code generated by gen_unboxed.go; DO NOT EDIT.
+
+package segmentedSlice
+
+import "fmt"
+{{range .}}
+// {{.Name}} is a segmented slice of {{.Type}} backed by []{{.Type}} segments.
+type {{.Name}} struct {
+	segIndex
+	data [][]{{.Type}}
+}
+
+// New{{.Name}} returns a new {{.Name}} with the specified segment length, it must be a power of two.
+func New{{.Name}}(segLen int) *{{.Name}} {
+	return &{{.Name}}{segIndex: newSegIndex(segLen)}
+}
+
+// Append appends vals to the slice.
+func (s *{{.Name}}) Append(vals ...{{.Type}}) {
+	for len(vals) > 0 {
+		di, si := s.index(s.len)
+		if di == len(s.data) {
+			s.data = append(s.data, make([]{{.Type}}, s.segLen+1))
+		}
+		n := copy(s.data[di][si:], vals)
+		vals, s.len = vals[n:], s.len+n
+	}
+}
+
+// Get returns the item at the specified index, it panics if i is out of range.
+func (s *{{.Name}}) Get(i int) {{.Type}} {
+	s.checkIndex(i)
+	di, si := s.index(i)
+	return s.data[di][si]
+}
+
+// Set sets the value at the specified index, it panics if i is out of range.
+func (s *{{.Name}}) Set(i int, v {{.Type}}) {
+	s.checkIndex(i)
+	di, si := s.index(i)
+	s.data[di][si] = v
+}
+
+// Pop deletes and returns the last item in the slice, it panics if the slice is empty.
+func (s *{{.Name}}) Pop() (v {{.Type}}) {
+	s.checkIndex(s.len - 1)
+	s.len--
+	di, si := s.index(s.len)
+	var zero {{.Type}}
+	v, s.data[di][si] = s.data[di][si], zero
+	return
+}
+
+// Cap returns the max number of elements the slice can hold before growing.
+func (s *{{.Name}}) Cap() int { return len(s.data) * (s.segLen + 1) }
+
+// ForEach loops over the slice and calls fn for each element.
+// If fn returns true, it breaks early and returns true otherwise returns false.
+func (s *{{.Name}}) ForEach(fn func(i int, v {{.Type}}) (breakNow bool)) bool {
+	return s.forEachSeg(len(s.data), func(di, off, n int) bool {
+		for si, v := range s.data[di][:n] {
+			if fn(off+si, v) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ToSlice returns the elements of the slice as a plain []{{.Type}}.
+func (s *{{.Name}}) ToSlice() []{{.Type}} {
+	out := make([]{{.Type}}, 0, s.len)
+	s.forEachSeg(len(s.data), func(di, _, n int) (_ bool) {
+		out = append(out, s.data[di][:n]...)
+		return
+	})
+	return out
+}
+
+func (s *{{.Name}}) String() string { return fmt.Sprint(s.ToSlice()) }
+{{end}}`))
+
+func main() {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, types); err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err = ioutil.WriteFile("unboxed_types.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
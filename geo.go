@@ -0,0 +1,76 @@
+package segmentedSlice
+
+// geoLayout describes a slice where the segments double in length from a small first segment up to segLen,
+// see WithGeometricSegments.
+type geoLayout struct {
+	first uint // shift of the length of the first segment
+	n     int  // number of growing segments, the following ones are segLen long
+	total int  // number of elements in the growing segments
+}
+
+// WithGeometricSegments makes every new segment twice as long as the previous one, starting at firstSegLen
+// and up to the segment length passed to New, so small slices stay tiny while huge slices need few segments.
+// firstSegLen must be a power of two and not larger than the slice's segment length.
+// Example:
+// 	ss := New(1<<16, WithGeometricSegments(8)) // segments of 8, 16, 32 ... 65536, 65536 ...
+func WithGeometricSegments(firstSegLen int) Option {
+	if !isPowerOfTwo(firstSegLen) {
+		panic("segLen is not power of two")
+	}
+
+	return func(ss *Slice) {
+		if firstSegLen > ss.segLen+1 {
+			panic("first segment length must be <= segLen")
+		}
+		first := findShift(firstSegLen)
+		ss.geo = &geoLayout{
+			first: first,
+			n:     int(ss.shift - first),
+			total: ss.segLen + 1 - firstSegLen,
+		}
+	}
+}
+
+// geoIndex is index for slices with geometric segments.
+func (ss *Slice) geoIndex(i int) (int, int) {
+	g := ss.geo
+	if i < g.total {
+		// the growing segments before k hold 1<<(first+k) - 1<<first elements
+		j := i + 1<<g.first
+		k := findShift(j)
+		return int(k - g.first), j - 1<<k
+	}
+	i -= g.total
+	return g.n + i>>ss.shift, i & ss.segLen
+}
+
+// segStart returns the index of the first element of the segment di, di may be len(ss.data).
+func (ss *Slice) segStart(di int) int {
+	if g := ss.geo; g != nil {
+		if di < g.n {
+			return 1<<(g.first+uint(di)) - 1<<g.first
+		}
+		return g.total + (di-g.n)<<ss.shift
+	}
+	return di << ss.shift
+}
+
+// segSize returns the length of the segment di.
+func (ss *Slice) segSize(di int) int {
+	if g := ss.geo; g != nil && di < g.n {
+		return 1 << (g.first + uint(di))
+	}
+	return ss.segLen + 1
+}
+
+// segmentsCovering returns the number of segments holding the first n elements.
+func (ss *Slice) segmentsCovering(n int) int {
+	if ss.geo == nil {
+		return (n + ss.segLen) >> ss.shift
+	}
+	if n == 0 {
+		return 0
+	}
+	di, _ := ss.geoIndex(n - 1)
+	return di + 1
+}
@@ -1,9 +1,69 @@
 package segmentedSlice
 
+import (
+	"fmt"
+	"sync"
+)
+
 // Iterator is a SegmentedSlice iterator.
 type Iterator struct {
 	ss         *Slice
+	perm       []int
 	start, end int
+	lo, hi     int // the range the iterator was created with
+	reverse    bool
+	mods       uint
+	c          cursor
+}
+
+// NewIterVal returns an Iterator by value rather than a pointer like IterAt does,
+// so tight loops that create many short-lived iterators don't allocate.
+// It panics if [start, end) isn't a valid range of ss.
+// Example:
+// 	it := NewIterVal(ss, 0, ss.Len())
+// 	for it.More() {
+// 		log.Println(it.Next())
+// 	}
+func NewIterVal(ss *Slice, start, end int) Iterator {
+	ss.checkRange(start, end)
+	return Iterator{
+		ss:    ss,
+		start: start,
+		end:   end,
+		lo:    start,
+		hi:    end,
+		mods:  ss.mods,
+	}
+}
+
+var iterPool = sync.Pool{
+	New: func() interface{} { return new(Iterator) },
+}
+
+// AcquireIter returns a pooled Iterator over ss[start:end], it must be returned to the pool with Release once done.
+// It is meant for tight loops that create many short-lived iterators, see also NewIterVal.
+// It panics if [start, end) isn't a valid range of ss.
+// Example:
+// 	it := AcquireIter(ss, 0, ss.Len())
+// 	for it.More() {
+// 		log.Println(it.Next())
+// 	}
+// 	it.Release()
+func AcquireIter(ss *Slice, start, end int) *Iterator {
+	it := iterPool.Get().(*Iterator)
+	it.ResetTo(ss, start, end)
+	return it
+}
+
+// Release returns an Iterator created by AcquireIter to the pool, it must not be used afterwards.
+func (it *Iterator) Release() {
+	*it = Iterator{}
+	iterPool.Put(it)
+}
+
+// ResetTo resets the iterator to iterate over ss[start:end], so it can be reused.
+func (it *Iterator) ResetTo(ss *Slice, start, end int) {
+	*it = NewIterVal(ss, start, end)
 }
 
 // More returns true if the iterator have more items/
@@ -11,16 +71,187 @@ func (it *Iterator) More() bool {
 	return it.start < it.end
 }
 
+// Len returns the number of items left in the iterator.
+func (it *Iterator) Len() int {
+	return it.end - it.start
+}
+
+// Remaining is an alias for Len.
+func (it *Iterator) Remaining() int { return it.Len() }
+
+// Seek moves the iterator to the i-th element of its range, the next call to Next returns it.
+// For a reverse iterator, i counts from the end. It panics if i is out of range, i == the range's length is allowed.
+func (it *Iterator) Seek(i int) {
+	if n := it.hi - it.lo; i < 0 || i > n {
+		panic(fmt.Sprintf("seek index out of range [%d] with length %d", i, n))
+	}
+
+	if it.reverse {
+		it.start, it.end = it.lo, it.hi-i
+	} else {
+		it.start, it.end = it.lo+i, it.hi
+	}
+}
+
+// Reset moves the iterator back to its first element, so the range can be iterated again.
+func (it *Iterator) Reset() { it.Seek(0) }
+
 // Next returns the next item.
 func (it *Iterator) Next() (val interface{}) {
-	val = it.ss.Get(it.start)
-	it.start++
+	_, val = it.NextIndex()
 	return
 }
 
 // NextIndex returns the next item and index.
+// It panics if elements were appended to or deleted from the slice since the iterator was created.
 func (it *Iterator) NextIndex() (idx int, val interface{}) {
-	idx, val = it.start, it.ss.Get(it.start)
-	it.start++
+	idx, val = it.PeekIndex()
+	if it.reverse {
+		it.end--
+	} else {
+		it.start++
+	}
+	return
+}
+
+// Peek returns the next item without advancing the iterator.
+func (it *Iterator) Peek() (val interface{}) {
+	_, val = it.PeekIndex()
+	return
+}
+
+// PeekIndex returns the next item and index without advancing the iterator.
+// It panics if the iterator has no more items.
+func (it *Iterator) PeekIndex() (idx int, val interface{}) {
+	if !it.More() {
+		panic("no more items")
+	}
+	it.ss.checkMods(it.mods)
+	if idx = it.start; it.reverse {
+		idx = it.end - 1
+	}
+
+	if it.perm != nil {
+		idx = it.perm[idx]
+		it.ss.checkIndex(idx)
+	}
+	val = it.ss.load(*it.c.ptr(it.ss, idx))
+	return
+}
+
+// SeqReader provides Get and Set for mostly sequential access patterns,
+// it caches the current segment and only does the index math when crossing a segment boundary.
+// A SeqReader must not be used after its slice has been modified by anything other than Set.
+type SeqReader struct {
+	ss *Slice
+	c  cursor
+}
+
+// SeqReader returns a SeqReader for the slice.
+// Example:
+// 	r := ss.SeqReader()
+// 	for i := 0; i < ss.Len(); i++ {
+// 		log.Println(r.Get(i))
+// 	}
+func (ss *Slice) SeqReader() *SeqReader {
+	return &SeqReader{ss: ss}
+}
+
+// Get returns the item at the specified index, it panics if i is out of range.
+func (r *SeqReader) Get(i int) interface{} {
+	r.ss.checkIndex(i)
+	return r.ss.load(*r.c.ptr(r.ss, i))
+}
+
+// Set sets the value at the specified index, it panics if i is out of range.
+func (r *SeqReader) Set(i int, v interface{}) {
+	r.ss.checkIndex(i)
+	r.ss.own(i, i+1)
+	*r.c.ptr(r.ss, i) = r.ss.store(v)
+}
+
+// cursor caches the segment of the last accessed index.
+type cursor struct {
+	seg []interface{}
+	di  int
+	off int // index of seg[0] relative to the slice, may be negative for sub-slices
+}
+
+// ptr returns a pointer to the element i, the cached segment is only used if it is still in the slice,
+// it gets replaced when a segment shared with a snapshot is copied on write (see own).
+func (c *cursor) ptr(ss *Slice, i int) *interface{} {
+	if j := i - c.off; uint(j) < uint(len(c.seg)) && c.di < len(ss.data) && sameSegment(ss.data[c.di], c.seg) {
+		return &c.seg[j]
+	}
+
+	di, si := ss.index(ss.baseIdx + i)
+	c.seg, c.di, c.off = ss.data[di], di, i-si
+	return &c.seg[si]
+}
+
+// sameSegment returns true if a and b start at the same element.
+func sameSegment(a, b []interface{}) bool {
+	return len(a) > 0 && len(b) > 0 && &a[0] == &b[0]
+}
+
+// MultiIterator iterates several slices as one sequence, see ChainIter.
+type MultiIterator struct {
+	its []Iterator
+	cur int
+	pos int
+}
+
+// ChainIter returns a MultiIterator over all the elements of slices, in order.
+// Example:
+// 	for it := ChainIter(monday, tuesday, wednesday); it.More(); {
+// 		log.Println(it.Next())
+// 	}
+func ChainIter(slices ...*Slice) *MultiIterator {
+	its := make([]Iterator, len(slices))
+	for i, ss := range slices {
+		its[i] = NewIterVal(ss, 0, ss.Len())
+	}
+	return &MultiIterator{its: its}
+}
+
+// More returns true if the iterator have more items.
+func (m *MultiIterator) More() bool {
+	for ; m.cur < len(m.its); m.cur++ {
+		if m.its[m.cur].More() {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of items left in the iterator.
+func (m *MultiIterator) Len() (n int) {
+	for i := m.cur; i < len(m.its); i++ {
+		n += m.its[i].Len()
+	}
+	return
+}
+
+// Next returns the next item.
+func (m *MultiIterator) Next() (val interface{}) {
+	_, _, val = m.NextSource()
 	return
 }
+
+// NextIndex returns the next item and its position in the whole sequence.
+func (m *MultiIterator) NextIndex() (idx int, val interface{}) {
+	idx = m.pos
+	_, _, val = m.NextSource()
+	return
+}
+
+// NextSource returns the next item, the index of the slice it came from in the slices passed to ChainIter
+// and its index in that slice.
+func (m *MultiIterator) NextSource() (src, idx int, val interface{}) {
+	if !m.More() {
+		panic("no more items")
+	}
+	idx, val = m.its[m.cur].NextIndex()
+	m.pos++
+	return m.cur, idx, val
+}
@@ -0,0 +1,97 @@
+//go:build go1.23
+// +build go1.23
+
+package segmentedSlice
+
+import "iter"
+
+// Pairs returns an iterator over the index-value pairs of the slice, in order,
+// it is the equivalent of slices.All (All is the predicate on Slice).
+// Example:
+// 	for i, v := range ss.Pairs() {
+// 		log.Println(i, v)
+// 	}
+func (ss *Slice) Pairs() iter.Seq2[int, interface{}] {
+	return func(yield func(int, interface{}) bool) {
+		ss.ForEach(func(i int, v interface{}) bool { return !yield(i, v) })
+	}
+}
+
+// Values returns an iterator over the values of the slice, in order.
+func (ss *Slice) Values() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		ss.ForEach(func(_ int, v interface{}) bool { return !yield(v) })
+	}
+}
+
+// Backward returns an iterator over the index-value pairs of the slice, from the last element to the first.
+func (ss *Slice) Backward() iter.Seq2[int, interface{}] {
+	return func(yield func(int, interface{}) bool) {
+		ss.ForEachReverse(func(i int, v interface{}) bool { return !yield(i, v) })
+	}
+}
+
+// Runs returns an iterator over the [start, end) index ranges of the consecutive elements that match pred.
+// Example:
+// 	for start, end := range ss.Runs(func(v interface{}) bool { return v == nil }) {
+// 		log.Printf("gap at [%d:%d]", start, end)
+// 	}
+func (ss *Slice) Runs(pred func(v interface{}) bool) iter.Seq2[int, int] {
+	return func(yield func(start, end int) bool) {
+		start := -1
+		stopped := ss.ForEach(func(i int, v interface{}) (breakNow bool) {
+			if pred(v) {
+				if start == -1 {
+					start = i
+				}
+				return
+			}
+
+			if start != -1 {
+				breakNow, start = !yield(start, i), -1
+			}
+			return
+		})
+
+		if !stopped && start != -1 {
+			yield(start, ss.Len())
+		}
+	}
+}
+
+// Chunks returns an iterator over consecutive sub-slices of length n, the last one may be shorter.
+// The chunks are views of ss (see Slice), when n is a multiple of the segment length they line up with the segments.
+func (ss *Slice) Chunks(n int) iter.Seq[*Slice] {
+	if n < 1 {
+		panic("n must be > 0")
+	}
+
+	return func(yield func(*Slice) bool) {
+		for start := 0; start < ss.len; start += n {
+			end := start + n
+			if end > ss.len {
+				end = ss.len
+			}
+			if !yield(ss.Slice(start, end)) {
+				return
+			}
+		}
+	}
+}
+
+// Windows returns an iterator over all the overlapping sub-slices of length n, in order,
+// the windows are views of ss (see Slice) so nothing is copied.
+// If n > Len(), it yields nothing.
+func (ss *Slice) Windows(n int) iter.Seq[*Slice] {
+	if n < 1 {
+		panic("n must be > 0")
+	}
+
+	return func(yield func(*Slice) bool) {
+		for start := 0; start+n <= ss.len; start++ {
+			if !yield(ss.Slice(start, start+n)) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+//go:build go1.23
+// +build go1.23
+
+package segmentedSlice
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPairsValuesBackward(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 11; i++ {
+		l.Append(i)
+	}
+	sub := l.Slice(1, 10)
+
+	n := 0
+	for i, v := range sub.Pairs() {
+		if i != n || v != i+1 {
+			t.Fatalf("Pairs: unexpected pair %d %v", i, v)
+		}
+		n++
+	}
+
+	n = 0
+	for v := range sub.Values() {
+		if v != n+1 {
+			t.Fatalf("Values: expected %d, got %v", n+1, v)
+		}
+		if n++; n == 5 {
+			break
+		}
+	}
+
+	n = sub.Len()
+	for i, v := range sub.Backward() {
+		if n--; i != n || v != i+1 {
+			t.Fatalf("Backward: unexpected pair %d %v", i, v)
+		}
+	}
+	if n != 0 {
+		t.Fatalf("Backward stopped at %d", n)
+	}
+
+	for range New(4).Backward() {
+		t.Fatal("expected no elements")
+	}
+}
+
+func TestRuns(t *testing.T) {
+	l := New(4)
+	l.Append(nil, 1, 2, nil, nil, nil, nil, nil, 3, nil)
+
+	var got [][2]int
+	for start, end := range l.Runs(func(v interface{}) bool { return v == nil }) {
+		got = append(got, [2]int{start, end})
+	}
+
+	exp := [][2]int{{0, 1}, {3, 8}, {9, 10}}
+	if len(got) != len(exp) {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+	for i := range exp {
+		if got[i] != exp[i] {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+	}
+
+	for start, end := range l.Slice(4, 9).Runs(func(v interface{}) bool { return v == nil }) {
+		if start != 0 || end != 4 {
+			t.Fatalf("expected [0:4], got [%d:%d]", start, end)
+		}
+		break
+	}
+}
+
+func TestChunks(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 10; i++ {
+		l.Append(i)
+	}
+
+	var got []string
+	for c := range l.Chunks(4) {
+		got = append(got, c.String())
+	}
+
+	if exp := "[[0, 1, 2, 3] [4, 5, 6, 7] [8, 9]]"; fmt.Sprint(got) != exp {
+		t.Fatalf("expected %s, got %v", exp, got)
+	}
+}
+
+func TestWindows(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 6; i++ {
+		l.Append(i)
+	}
+
+	var sums []int
+	for w := range l.Windows(3) {
+		sums = append(sums, w.Reduce(0, func(acc, v interface{}) interface{} { return acc.(int) + v.(int) }).(int))
+	}
+
+	if exp := "[3 6 9 12]"; fmt.Sprint(sums) != exp {
+		t.Fatalf("expected %s, got %v", exp, sums)
+	}
+
+	for range l.Windows(7) {
+		t.Fatal("expected no windows")
+	}
+}
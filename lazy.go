@@ -0,0 +1,20 @@
+package segmentedSlice
+
+// WithLazySegments makes Grow (and Reserve) only extend the segment directory, the segments themselves are allocated
+// on the first write into them, so reserving a large capacity for a mostly empty slice doesn't commit the memory.
+// Example:
+// 	ss := New(1024, WithLazySegments())
+// 	ss.Reserve(10000000) // no segments are allocated yet
+func WithLazySegments() Option {
+	return func(ss *Slice) { ss.lazy = true }
+}
+
+// allocated returns the number of segments that are allocated.
+func (ss *Slice) allocated() (n int) {
+	for _, seg := range ss.data {
+		if seg != nil {
+			n++
+		}
+	}
+	return
+}
@@ -0,0 +1,42 @@
+package segmentedSlice
+
+import "expvar"
+
+// Metrics receives usage counters from the slices it is attached to with WithMetrics,
+// it must be safe for concurrent use if it is shared by slices used from different goroutines.
+type Metrics interface {
+	// Appended is called with the number of appended elements.
+	Appended(n int)
+	// Grew is called with the number of segments added by Grow.
+	Grew(segments int)
+	// Copied is called with the number of copied elements when a sub-slice turns into an independent slice.
+	Copied(n int)
+}
+
+// WithMetrics reports the slice's appends, grows and sub-slice copies to m, slices created from it
+// (Copy, Rechunk, Split, etc) report to m as well.
+// Example:
+// 	ss := New(128, WithMetrics(NewExpvarMetrics("events")))
+func WithMetrics(m Metrics) Option {
+	return func(ss *Slice) { ss.metrics = m }
+}
+
+// ExpvarMetrics is a Metrics that publishes the counters as an expvar.Map
+// with the keys "appends", "grows" and "copies".
+type ExpvarMetrics struct {
+	m *expvar.Map
+}
+
+// NewExpvarMetrics returns an ExpvarMetrics published under name, like expvar.NewMap it panics if name is already used.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	return &ExpvarMetrics{m: expvar.NewMap(name)}
+}
+
+// Appended implements Metrics.
+func (e *ExpvarMetrics) Appended(n int) { e.m.Add("appends", int64(n)) }
+
+// Grew implements Metrics.
+func (e *ExpvarMetrics) Grew(segments int) { e.m.Add("grows", int64(segments)) }
+
+// Copied implements Metrics.
+func (e *ExpvarMetrics) Copied(n int) { e.m.Add("copies", int64(n)) }
@@ -0,0 +1,95 @@
+package segmentedSlice
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+
+	idx int
+	v   interface{}
+}
+
+// ApplyJSONPatch applies an RFC 6902 JSON Patch to the slice.
+// Only the add, remove and replace operations are supported, paths must be array indices ("/3") or "/-" for add.
+// Values are decoded the same way as UnmarshalJSON.
+// The patch is validated before being applied, if it returns an error the slice isn't modified.
+// Example:
+// 	ss.ApplyJSONPatch([]byte(`[{"op": "replace", "path": "/0", "value": 42}, {"op": "add", "path": "/-", "value": 1}]`))
+func (ss *Slice) ApplyJSONPatch(patch []byte) error {
+	var ops []*patchOp
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return err
+	}
+
+	n := ss.len
+	for i, op := range ops {
+		if op.Op != "add" && op.Op != "remove" && op.Op != "replace" {
+			return fmt.Errorf("op %d: unsupported op: %q", i, op.Op)
+		}
+
+		if op.Op == "add" && op.Path == "/-" {
+			op.idx = n
+		} else {
+			idx, err := parsePatchIndex(op.Path)
+			if err != nil {
+				return fmt.Errorf("op %d: %v", i, err)
+			}
+			if idx > n || idx == n && op.Op != "add" {
+				return fmt.Errorf("op %d: index out of range [%d] with length %d", i, idx, n)
+			}
+			op.idx = idx
+		}
+
+		switch op.Op {
+		case "add":
+			n++
+		case "remove":
+			n--
+			continue
+		}
+
+		if len(op.Value) == 0 {
+			return fmt.Errorf("op %d: missing value", i)
+		}
+
+		v, _, err := ss.decodeElem(json.NewDecoder(bytes.NewReader(op.Value)))
+		if err != nil {
+			return fmt.Errorf("op %d: %v", i, err)
+		}
+		op.v = v
+	}
+
+	for _, op := range ops {
+		switch op.Op {
+		case "add":
+			ss.insert(op.idx, op.v)
+		case "remove":
+			ss.remove(op.idx)
+		case "replace":
+			ss.Set(op.idx, op.v)
+		}
+	}
+
+	return nil
+}
+
+// parsePatchIndex parses a JSON Pointer array index, leading zeros aren't allowed per RFC 6901.
+func parsePatchIndex(path string) (int, error) {
+	if len(path) < 2 || path[0] != '/' || (path[1] == '0' && len(path) > 2) {
+		return 0, fmt.Errorf("invalid path: %q", path)
+	}
+
+	idx, err := strconv.ParseUint(path[1:], 10, 31)
+	if err != nil {
+		return 0, fmt.Errorf("invalid path: %q", path)
+	}
+
+	return int(idx), nil
+}
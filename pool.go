@@ -0,0 +1,41 @@
+package segmentedSlice
+
+import "sync"
+
+// SegmentPool recycles segments between slices, it is safe for concurrent use.
+// The segments released by Reset, Truncate and Compact of a slice using the pool are reused by the next slice
+// that grows, see WithSegmentPool. The zero value is ready to use.
+type SegmentPool struct {
+	pools [64]sync.Pool // indexed by the shift of the segment length
+}
+
+// NewSegmentPool returns a new SegmentPool.
+func NewSegmentPool() *SegmentPool { return &SegmentPool{} }
+
+// WithSegmentPool makes the slice take its segments from p and return them to it when they are released,
+// so servers that constantly build and discard slices of similar sizes don't keep allocating segments.
+// Sub-slices must not be used after their parent released the segments they point to.
+// Example:
+// 	var pool = NewSegmentPool()
+// 	ss := New(1024, WithSegmentPool(pool))
+// 	// use ss
+// 	ss.Reset() // the segments go back to the pool
+func WithSegmentPool(p *SegmentPool) Option {
+	return func(ss *Slice) { ss.pool = p }
+}
+
+func (p *SegmentPool) get(segLen int) []interface{} {
+	if seg, ok := p.pools[findShift(segLen)].Get().(*[]interface{}); ok {
+		return *seg
+	}
+	return make([]interface{}, segLen)
+}
+
+// put clears seg and adds it to the pool, seg must not be used after that.
+func (p *SegmentPool) put(seg []interface{}) {
+	seg = seg[:cap(seg)]
+	for i := range seg {
+		seg[i] = nil
+	}
+	p.pools[findShift(len(seg))].Put(&seg)
+}
@@ -0,0 +1,76 @@
+package segmentedSlice
+
+import "math/rand"
+
+// Shuffle randomizes the order of the elements in place using the Fisher–Yates algorithm.
+// If r is nil, the default source from math/rand is used.
+func (ss *Slice) Shuffle(r *rand.Rand) {
+	intn := rand.Intn
+	if r != nil {
+		intn = r.Intn
+	}
+
+	ss.own(0, ss.len)
+	for i := ss.len - 1; i > 0; i-- {
+		a, b := ss.ptrAt(ss.baseIdx+i), ss.ptrAt(ss.baseIdx+intn(i+1))
+		*a, *b = *b, *a
+	}
+}
+
+// Sample returns a new Slice with n elements chosen uniformly at random without replacement,
+// the elements keep their relative order. If n >= Len(), all the elements are returned.
+// If r is nil, the default source from math/rand is used.
+func (ss *Slice) Sample(r *rand.Rand, n int) *Slice {
+	intn := rand.Intn
+	if r != nil {
+		intn = r.Intn
+	}
+
+	if n > ss.len {
+		n = ss.len
+	}
+
+	nss := ss.newEmpty()
+	if n <= 0 {
+		return nss
+	}
+
+	nss.Grow(n)
+	left := ss.len
+	ss.ForEach(func(_ int, v interface{}) bool {
+		// select v with probability (needed / left)
+		if intn(left) < n {
+			nss.Append(v)
+			n--
+		}
+		left--
+		return n == 0
+	})
+
+	return nss
+}
+
+// ReservoirAppend keeps a uniform random sample of at most n elements out of a stream of unknown length.
+// seen is the number of elements of the stream processed so far, and the updated count is returned.
+// If r is nil, the default source from math/rand is used.
+// Example:
+// 	var seen int
+// 	for v := range input {
+// 		seen = ss.ReservoirAppend(nil, 100, seen, v)
+// 	}
+func (ss *Slice) ReservoirAppend(r *rand.Rand, n, seen int, vals ...interface{}) int {
+	intn := rand.Intn
+	if r != nil {
+		intn = r.Intn
+	}
+
+	for _, v := range vals {
+		if seen++; ss.len < n {
+			ss.Append(v)
+		} else if j := intn(seen); j < n {
+			ss.Set(j, v)
+		}
+	}
+
+	return seen
+}
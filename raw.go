@@ -0,0 +1,129 @@
+package segmentedSlice
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// RawSlice stores fixed-size, pointer-free values (numbers, bools and arrays or structs of them) directly
+// in []byte segments, so appending doesn't allocate per element and the GC doesn't have to scan the segments.
+type RawSlice struct {
+	segIndex
+	typ  reflect.Type
+	size int
+	data [][]byte
+}
+
+// NewRawSlice returns a new RawSlice holding values of the same type as sample, segLen must be a power of two.
+// It panics if the type contains pointers (pointers, strings, slices, maps, interfaces, channels or funcs).
+// Example:
+// 	rs := NewRawSlice(1024, Sample{})
+// 	rs.Append(Sample{TS: 1, Value: 2.5})
+// 	var s Sample
+// 	rs.Load(0, &s)
+func NewRawSlice(segLen int, sample interface{}) *RawSlice {
+	typ := reflect.TypeOf(sample)
+	if typ == nil || !isPointerFree(typ) {
+		panic(fmt.Sprintf("%v is not a pointer-free type", typ))
+	}
+
+	return &RawSlice{
+		segIndex: newSegIndex(segLen),
+		typ:      typ,
+		size:     int(typ.Size()),
+	}
+}
+
+// Append appends v, which must be a value of the slice's type or a pointer to one.
+// Passing a pointer avoids boxing the value in an interface.
+func (rs *RawSlice) Append(v interface{}) {
+	di, si := rs.index(rs.len)
+	if di == len(rs.data) {
+		rs.data = append(rs.data, make([]byte, (rs.segLen+1)*rs.size))
+	}
+	rs.len++
+	copy(rs.slot(di, si), rs.bytesOf(v))
+}
+
+// Set sets the value at the specified index, v must be a value of the slice's type or a pointer to one.
+// It panics if i is out of range.
+func (rs *RawSlice) Set(i int, v interface{}) {
+	rs.checkIndex(i)
+	di, si := rs.index(i)
+	copy(rs.slot(di, si), rs.bytesOf(v))
+}
+
+// Get returns a copy of the value at the specified index, it panics if i is out of range.
+// Use Load to avoid boxing the value.
+func (rs *RawSlice) Get(i int) interface{} {
+	p := reflect.New(rs.typ)
+	rs.Load(i, p.Interface())
+	return p.Elem().Interface()
+}
+
+// Load copies the value at the specified index into dst, which must be a pointer to the slice's type.
+// It panics if i is out of range.
+func (rs *RawSlice) Load(i int, dst interface{}) {
+	rs.checkIndex(i)
+	di, si := rs.index(i)
+	copy(rs.bytesOf(dst), rs.slot(di, si))
+}
+
+// ElemSize returns the size in bytes of an element.
+func (rs *RawSlice) ElemSize() int { return rs.size }
+
+// Cap returns the max number of elements the slice can hold before growing.
+func (rs *RawSlice) Cap() int { return len(rs.data) * (rs.segLen + 1) }
+
+func (rs *RawSlice) slot(di, si int) []byte {
+	off := si * rs.size
+	return rs.data[di][off : off+rs.size]
+}
+
+// bytesOf returns the memory of v, which must be of the slice's type or a pointer to it.
+// Non-pointer values are copied by the interface conversion, so writing to the returned bytes only works with pointers.
+func (rs *RawSlice) bytesOf(v interface{}) []byte {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && rv.Type().Elem() == rs.typ {
+		if rv.IsNil() {
+			panic("nil pointer")
+		}
+		return rawBytes(unsafe.Pointer(rv.Pointer()), rs.size)
+	}
+
+	if rv.Type() != rs.typ {
+		panic(fmt.Sprintf("expected %v, got %T", rs.typ, v))
+	}
+	p := reflect.New(rs.typ)
+	p.Elem().Set(rv)
+	return rawBytes(unsafe.Pointer(p.Pointer()), rs.size)
+}
+
+func rawBytes(p unsafe.Pointer, size int) []byte {
+	if size == 0 {
+		return nil
+	}
+	return (*[1 << 30]byte)(p)[:size:size]
+}
+
+// isPointerFree returns true if values of typ don't hold any pointers.
+func isPointerFree(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return isPointerFree(typ.Elem())
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			if !isPointerFree(typ.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package segmentedSlice
+
+import "testing"
+
+type rawSample struct {
+	TS    int64
+	Value float64
+	Tags  [2]uint16
+	OK    bool
+}
+
+func TestRawSlice(t *testing.T) {
+	rs := NewRawSlice(4, rawSample{})
+	for i := 0; i < 10; i++ {
+		rs.Append(rawSample{TS: int64(i), Value: float64(i) / 2, Tags: [2]uint16{uint16(i), 1}, OK: i%2 == 0})
+	}
+	rs.Append(&rawSample{TS: 10})
+	rs.Set(3, rawSample{TS: -3})
+
+	if rs.Len() != 11 || rs.Cap() != 12 {
+		t.Fatalf("unexpected len/cap: %d %d", rs.Len(), rs.Cap())
+	}
+
+	var s rawSample
+	rs.Load(9, &s)
+	if exp := (rawSample{9, 4.5, [2]uint16{9, 1}, false}); s != exp {
+		t.Fatalf("expected %+v, got %+v", exp, s)
+	}
+	if v := rs.Get(3).(rawSample); v.TS != -3 || v.Value != 0 {
+		t.Fatalf("unexpected Get: %+v", v)
+	}
+	if v := rs.Get(10).(rawSample); v.TS != 10 {
+		t.Fatalf("unexpected Get: %+v", v)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		rs.Load(5, &s)
+		rs.Set(5, &s)
+	})
+	if allocs != 0 {
+		t.Fatalf("expected no allocations, got %v", allocs)
+	}
+
+	for _, v := range []interface{}{"x", struct{ P *int }{}, []int{}, nil} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%T: expected a panic", v)
+				}
+			}()
+			NewRawSlice(4, v)
+		}()
+	}
+}
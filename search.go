@@ -0,0 +1,50 @@
+package segmentedSlice
+
+import "sort"
+
+// BuildSearchIndex builds a sorted permutation of the slice's indices using the less function passed to NewSortable,
+// without moving any data, so SearchIndexed can do O(log n) lookups while the slice keeps its insertion order.
+// The index isn't updated when the slice is modified, it is meant for read-mostly slices and must be rebuilt after any modification.
+func (ss *Slice) BuildSearchIndex() {
+	if ss.lessFn == nil {
+		panic("lessFn is nil, use NewSortable")
+	}
+
+	perm := make([]int, ss.len)
+	for i := range perm {
+		perm[i] = i
+	}
+	sort.Stable(&permSorter{ss: ss, perm: perm})
+	ss.searchIdx = perm
+}
+
+// SearchIndexed returns the index of the first element equal to v (neither is less than the other), or -1 if there isn't one.
+// It panics if BuildSearchIndex wasn't called or the slice's length changed since.
+// It is safe to call concurrently as long as the slice isn't modified.
+func (ss *Slice) SearchIndexed(v interface{}) int {
+	perm := ss.searchIdx
+	if perm == nil {
+		panic("BuildSearchIndex must be called before SearchIndexed")
+	}
+	if len(perm) != ss.len {
+		panic("the search index is stale, BuildSearchIndex must be called after modifying the slice")
+	}
+
+	i := sort.Search(len(perm), func(i int) bool { return !ss.lessFn(ss.Get(perm[i]), v) })
+	if i < len(perm) && !ss.lessFn(v, ss.Get(perm[i])) {
+		return perm[i]
+	}
+	return -1
+}
+
+// permSorter sorts a permutation of a Slice's indices.
+type permSorter struct {
+	ss   *Slice
+	perm []int
+}
+
+func (p *permSorter) Len() int      { return len(p.perm) }
+func (p *permSorter) Swap(i, j int) { p.perm[i], p.perm[j] = p.perm[j], p.perm[i] }
+func (p *permSorter) Less(i, j int) bool {
+	return p.ss.lessFn(p.ss.Get(p.perm[i]), p.ss.Get(p.perm[j]))
+}
@@ -0,0 +1,185 @@
+package segmentedSlice
+
+// StealFrom moves the elements of up to n whole segments from the tail of other to the end of ss
+// and returns the number of moved elements.
+// If both slices have the same fixed segment length and ss ends on a segment boundary, the segments are handed over
+// by pointer, otherwise the elements are copied.
+// If used on sub-slices, they turn into independent slices.
+func (ss *Slice) StealFrom(other *Slice, n int) int {
+	if n <= 0 || other.len == 0 {
+		return 0
+	}
+
+	ss.Grow(0)
+	other.Grow(0)
+	ss.unshare()
+	other.unshare()
+
+	if ss.len == 0 && !ss.sized {
+		ss.segLen, ss.shift, ss.sized = other.segLen, other.shift, other.sized
+	}
+
+	segLen := other.segLen + 1
+	used := other.segmentsCovering(other.len)
+	if n > used {
+		n = used
+	}
+	keep := used - n
+	keepLen := other.segStart(keep)
+	moved := other.len - keepLen
+
+	if ss.segLen != other.segLen || ss.len&ss.segLen != 0 || ss.geo != nil || other.geo != nil {
+		ss.Grow(moved)
+		other.ForEachAt(keepLen, func(_ int, v interface{}) (_ bool) {
+			ss.Append(v)
+			return
+		})
+		other.clearRange(keepLen, other.len)
+		other.len = keepLen
+		other.mods++
+		return moved
+	}
+
+	head := ss.data[:ss.len>>ss.shift]
+	data := make([][]interface{}, 0, len(ss.data)+n)
+	data = append(data, head...)
+	data = append(data, other.data[keep:used]...)
+	data = append(data, ss.data[len(head):]...)
+	ss.data, ss.cap, ss.len = data, ss.cap+n*segLen, ss.len+moved
+	ss.mods++
+
+	// move other's spare segments down and drop the references to the stolen ones
+	spare := copy(other.data[keep:], other.data[used:])
+	tail := other.data[keep+spare:]
+	for i := range tail {
+		tail[i] = nil
+	}
+	other.data = other.data[:keep+spare]
+	other.cap -= n * segLen
+	other.len = keep * segLen
+	other.mods++
+
+	return moved
+}
+
+// Split splits the slice into n independent shards of roughly equal length by handing over whole segments,
+// the elements are only copied if ss is a sub-slice (see Grow) or has geometric segments.
+// The shards keep the order of the elements, and ss is left empty.
+func (ss *Slice) Split(n int) []*Slice {
+	if n < 1 {
+		panic("n must be > 0")
+	}
+
+	ss.Grow(0)
+	if ss.geo != nil {
+		return ss.splitCopy(n)
+	}
+	ss.unshare()
+
+	var (
+		shards = make([]*Slice, n)
+		segLen = ss.segLen + 1
+		used   = (ss.len + segLen - 1) / segLen
+		left   = ss.len
+		first  int
+	)
+
+	for i := range shards {
+		sh := ss.newEmpty()
+		shards[i] = sh
+
+		cnt := used / n
+		if i < used%n {
+			cnt++
+		}
+		if cnt == 0 {
+			continue
+		}
+
+		sh.data = ss.data[first : first+cnt : first+cnt]
+		sh.cap = cnt * segLen
+		if sh.len = sh.cap; sh.len > left {
+			sh.len = left
+		}
+		left -= sh.len
+		first += cnt
+	}
+
+	mods := ss.mods
+	*ss = *ss.newEmpty()
+	ss.mods = mods + 1
+	return shards
+}
+
+// splitCopy is Split for slices with geometric segments, every shard starts with the smallest segment again.
+func (ss *Slice) splitCopy(n int) []*Slice {
+	shards := make([]*Slice, n)
+	start := 0
+	for i := range shards {
+		cnt := ss.len / n
+		if i < ss.len%n {
+			cnt++
+		}
+		sh := ss.newEmpty()
+		sh.Grow(cnt)
+		sh.own(0, cnt)
+		ss.forEachSeg(start, start+cnt, func(_ int, seg []interface{}) (_ bool) {
+			for _, v := range seg {
+				*sh.ptrAt(sh.len) = v
+				sh.len++
+			}
+			return
+		})
+		shards[i] = sh
+		start += cnt
+	}
+
+	mods := ss.mods
+	*ss = *ss.newEmpty()
+	ss.mods = mods + 1
+	return shards
+}
+
+// Rechunk moves the elements of the slice into a new Slice with a segment length of newSegLen and leaves ss empty.
+// If newSegLen is the same or smaller than the current segment length, the segments are handed over (and split)
+// without copying any element, otherwise the elements are copied into the larger segments.
+// If used on a sub-slice, the elements are copied first (see Grow). The returned slice always has fixed length segments,
+// the elements of a slice with geometric segments are always copied.
+func (ss *Slice) Rechunk(newSegLen int) *Slice {
+	if !isPowerOfTwo(newSegLen) {
+		panic("segLen is not power of two")
+	}
+
+	ss.Grow(0)
+
+	nss := ss.newEmpty()
+	nss.segLen, nss.shift, nss.sized, nss.geo = newSegLen-1, findShift(newSegLen), true, nil
+
+	if segLen := ss.segLen + 1; newSegLen > segLen || ss.len == 0 || ss.geo != nil {
+		ss.forEachSeg(0, ss.len, func(_ int, seg []interface{}) (_ bool) {
+			nss.Grow(len(seg))
+			nss.own(nss.len, nss.len+len(seg))
+			for _, v := range seg {
+				*nss.ptrAt(nss.len) = v
+				nss.len++
+			}
+			return
+		})
+	} else {
+		used := (ss.len + ss.segLen) >> ss.shift
+		for di, seg := range ss.data[:used] {
+			for i := 0; i < segLen; i += newSegLen {
+				nss.data = append(nss.data, seg[i:i+newSegLen:i+newSegLen])
+				if ss.shared != nil {
+					nss.shared = append(nss.shared, ss.isShared(di))
+				}
+			}
+		}
+		nss.len, nss.cap = ss.len, used*segLen
+	}
+
+	mods := ss.mods
+	*ss = *ss.newEmpty()
+	ss.mods = mods + 1
+	return nss
+}
@@ -0,0 +1,53 @@
+package segmentedSlice
+
+// Union returns a new Slice with the unique elements of ss followed by the unique elements of other that aren't in ss,
+// where elements with the same hash are considered equal. The first occurrence of each element is kept.
+func (ss *Slice) Union(other *Slice, hash func(v interface{}) string) *Slice {
+	nss := ss.newEmpty()
+	seen := make(map[string]struct{})
+	add := func(_ int, v interface{}) (_ bool) {
+		h := hash(v)
+		if _, ok := seen[h]; !ok {
+			seen[h] = struct{}{}
+			nss.Append(v)
+		}
+		return
+	}
+	ss.ForEach(add)
+	other.ForEach(add)
+	return nss
+}
+
+// Intersect returns a new Slice with the unique elements of ss that are also in other, in the order of ss,
+// where elements with the same hash are considered equal.
+func (ss *Slice) Intersect(other *Slice, hash func(v interface{}) string) *Slice {
+	return ss.setFilter(other, hash, true)
+}
+
+// Difference returns a new Slice with the unique elements of ss that aren't in other, in the order of ss,
+// where elements with the same hash are considered equal.
+func (ss *Slice) Difference(other *Slice, hash func(v interface{}) string) *Slice {
+	return ss.setFilter(other, hash, false)
+}
+
+// setFilter returns the unique elements of ss that are (or aren't) in other.
+func (ss *Slice) setFilter(other *Slice, hash func(v interface{}) string, in bool) *Slice {
+	nss := ss.newEmpty()
+	set := make(map[string]bool, other.len)
+	other.ForEach(func(_ int, v interface{}) (_ bool) {
+		set[hash(v)] = true
+		return
+	})
+
+	seen := make(map[string]struct{})
+	ss.ForEach(func(_ int, v interface{}) (_ bool) {
+		h := hash(v)
+		if _, ok := seen[h]; ok || set[h] != in {
+			return
+		}
+		seen[h] = struct{}{}
+		nss.Append(v)
+		return
+	})
+	return nss
+}
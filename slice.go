@@ -3,31 +3,68 @@ package segmentedSlice
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 )
 
+const maxInt = int(^uint(0) >> 1)
+
 // DefaultSegmentLen is used if segLen is 0, mostly during an auto-constructed slice from JSON.
 var DefaultSegmentLen = 128
 
 // New returns a new Slice with the specified segment length.
 // Length must be a power of two or 0, if it is 0 it will use the DefaultSegmentLen.
-func New(segLen int) *Slice {
-	return NewSortable(segLen, nil)
+func New(segLen int, opts ...Option) *Slice {
+	return NewSortable(segLen, nil, opts...)
+}
+
+// NewWithCapacity returns a new Slice with enough segments preallocated to hold capacity elements, see Reserve.
+func NewWithCapacity(segLen, capacity int, opts ...Option) *Slice {
+	ss := New(segLen, opts...)
+	ss.Reserve(capacity)
+	return ss
+}
+
+// ErrInvalidSegLen is returned by NewChecked if the segment length isn't a power of two.
+var ErrInvalidSegLen = errors.New("segLen is not power of two")
+
+// NewChecked is like New but returns ErrInvalidSegLen rather than panicking if segLen isn't a power of two,
+// it is meant for segment lengths coming from user supplied configuration.
+func NewChecked(segLen int, opts ...Option) (*Slice, error) {
+	if !isPowerOfTwo(segLen) {
+		return nil, ErrInvalidSegLen
+	}
+	return New(segLen, opts...), nil
 }
 
 // NewSortable returns a Slice that supports the sort.Interface
 // Length must be a power of two or 0, if it is 0 it will use the DefaultSegmentLen.
-func NewSortable(segLen int, lessFn func(a, b interface{}) bool) *Slice {
+func NewSortable(segLen int, lessFn func(a, b interface{}) bool, opts ...Option) *Slice {
 	if !isPowerOfTwo(segLen) {
 		panic("segLen is not power of two")
 	}
 
-	return &Slice{
+	ss := &Slice{
 		segLen: segLen - 1,
 		shift:  findShift(segLen),
+		sized:  true,
 		lessFn: lessFn,
 	}
+
+	for _, opt := range opts {
+		opt(ss)
+	}
+
+	return ss
+}
+
+// Option configures a Slice on creation.
+type Option func(ss *Slice)
+
+// WithLess sets the less function used by Sort, the same as passing it to NewSortable.
+func WithLess(less func(a, b interface{}) bool) Option {
+	return func(ss *Slice) { ss.lessFn = less }
 }
 
 // Slice is a special slice-of-slices, when it grows it creates a new internal slice
@@ -38,36 +75,108 @@ type Slice struct {
 	segLen int
 
 	shift uint
+	sized bool       // segLen and shift are set, false for a zero value Slice (see DefaultSegmentLen)
+	geo   *geoLayout // nil unless the segments grow geometrically
 
 	baseIdx int
+	view    bool // set by Slice, the segments and their directory belong to the parent
 
 	data   [][]interface{}
 	lessFn func(a, b interface{}) bool
 
-	typ reflect.Type
+	searchIdx []int
+
+	shared []bool // shared[i] is true if data[i] is shared with a snapshot
+	frozen bool
+	lazy   bool // segments are allocated on the first write, see WithLazySegments
+
+	mods   uint // incremented on every change of the length, see checkMods
+	allocs int  // number of segment allocations, see Stats
+
+	typ   reflect.Type
+	uopts UnmarshalOptions
+	mode  ElemMode
+
+	enc, dec func(v interface{}) interface{}
+
+	pool    *SegmentPool
+	metrics Metrics
 }
 
-// Get returns the item at the specified index, if i > Cap(), it panics.
+// Get returns the item at the specified index, it panics if i is out of range.
 func (ss *Slice) Get(i int) interface{} {
-	di, si := ss.index(ss.baseIdx + i)
-	return ss.data[di][si]
+	ss.checkIndex(i)
+	return ss.load(ss.GetUnchecked(i))
 }
 
-// Set sets the value at the specified index, if i > Cap(), it panics.
+// Set sets the value at the specified index, it panics if i is out of range.
+// The value is converted according to the slice's ElemMode and codec.
 func (ss *Slice) Set(i int, v interface{}) {
-	*ss.ptrAt(ss.baseIdx + i) = v
+	ss.checkIndex(i)
+	ss.SetUnchecked(i, ss.store(v))
 }
 
-// Append appends vals to the slice.
-// If used on a sub-slice, it turns into an independent slice.
-func (ss *Slice) Append(vals ...interface{}) {
-	ss.Grow(len(vals))
-	for _, v := range vals {
-		*ss.ptrAt(ss.len) = v
-		ss.len++
+// GetE is like Get but returns an ErrIndexOutOfRange rather than panicking if i is out of range.
+func (ss *Slice) GetE(i int) (interface{}, error) {
+	if uint(i) >= uint(ss.len) {
+		return nil, ErrIndexOutOfRange{i, ss.len}
+	}
+	return ss.load(ss.GetUnchecked(i)), nil
+}
+
+// SetE is like Set but returns an ErrIndexOutOfRange rather than panicking if i is out of range.
+func (ss *Slice) SetE(i int, v interface{}) error {
+	if uint(i) >= uint(ss.len) {
+		return ErrIndexOutOfRange{i, ss.len}
+	}
+	ss.SetUnchecked(i, ss.store(v))
+	return nil
+}
+
+// ErrIndexOutOfRange is returned by GetE and SetE when the index is out of range.
+type ErrIndexOutOfRange struct {
+	Index int
+	Len   int
+}
+
+func (e ErrIndexOutOfRange) Error() string {
+	return fmt.Sprintf("index out of range [%d] with length %d", e.Index, e.Len)
+}
+
+// GetUnchecked is like Get but skips all validation, it is meant for profiled hot loops.
+// It also skips the codec and returns the stored value, which is the encoded value if the slice has a codec
+// (see SetCodec) and the deadline tagged value if the element has a deadline (see SetDeadline).
+// Using an index past Len() returns stale data or panics with a raw index out of range error.
+func (ss *Slice) GetUnchecked(i int) interface{} {
+	i += ss.baseIdx
+	if ss.geo != nil {
+		di, si := ss.geoIndex(i)
+		return ss.data[di][si]
+	}
+	return ss.data[i>>ss.shift][i&ss.segLen]
+}
+
+// SetUnchecked is like Set but skips all validation, it is meant for profiled hot loops.
+// It also skips the ElemMode conversion and the codec, v is stored as is, so it must already be the encoded value
+// if the slice has a codec (see SetCodec), and it drops the element's deadline.
+// Using an index past Len() silently writes past the end of the slice or panics with a raw index out of range error.
+func (ss *Slice) SetUnchecked(i int, v interface{}) {
+	if ss.shared != nil || ss.frozen || ss.lazy {
+		ss.own(i, i+1)
+	}
+	i += ss.baseIdx
+	if ss.geo != nil {
+		di, si := ss.geoIndex(i)
+		ss.data[di][si] = v
+		return
 	}
+	ss.data[i>>ss.shift][i&ss.segLen] = v
 }
 
+// Append appends vals to the slice, the values are converted according to the slice's ElemMode and codec.
+// If used on a sub-slice, it turns into an independent slice.
+func (ss *Slice) Append(vals ...interface{}) { ss.AppendSlice(vals) }
+
 // AppendTo appends all the data in the current slice to `other` and returns `other`.
 func (ss *Slice) AppendTo(oss *Slice) *Slice {
 	// TODO optimize
@@ -83,23 +192,58 @@ func (ss *Slice) AppendTo(oss *Slice) *Slice {
 // If used on a sub-slice, it turns into an independent slice.
 func (ss *Slice) Pop() (v interface{}) {
 	ss.Grow(0)
+	ss.own(ss.len-1, ss.len)
 	p := ss.ptrAt(ss.len - 1)
 	v = *p
 	*p = nil
 	ss.len--
-	return v
+	ss.mods++
+	return ss.load(v)
+}
+
+// TryPop is like Pop but returns nil and false rather than panicking if the slice is empty.
+func (ss *Slice) TryPop() (v interface{}, ok bool) {
+	if ss.len == 0 {
+		return nil, false
+	}
+	return ss.Pop(), true
+}
+
+// PopN deletes and returns the last n items in the slice in their original order,
+// if the slice has less than n items, all of them are returned.
+// If used on a sub-slice, it turns into an independent slice.
+func (ss *Slice) PopN(n int) []interface{} {
+	if n > ss.len {
+		n = ss.len
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	out := ss.GetRange(ss.len-n, ss.len)
+	ss.Truncate(ss.len-n, false)
+	return out
 }
 
 // ForEachAt loops over the slice and calls fn for each element.
 // If fn returns true, it breaks early and returns true otherwise returns false.
+// It panics if fn appends or deletes elements.
 func (ss *Slice) ForEachAt(i int, fn func(i int, v interface{}) (breakNow bool)) bool {
+	if i >= ss.len {
+		return false
+	}
+
+	mods := ss.mods
+
 	di, si := ss.index(ss.baseIdx + i)
 	for dii := di; dii < len(ss.data); dii++ {
 		s := ss.data[dii]
 		for sii := si; sii < len(s); sii++ {
-			if fn(i, s[sii]) {
+			if fn(i, ss.load(s[sii])) {
 				return true
 			}
+			ss.checkMods(mods)
+			s = ss.data[dii] // fn may have replaced a shared segment, see own
 			if i++; i == ss.len {
 				return false
 			}
@@ -110,52 +254,200 @@ func (ss *Slice) ForEachAt(i int, fn func(i int, v interface{}) (breakNow bool))
 	return false
 }
 
+// ForEachSegment calls fn with the parts of the backing segments that hold the elements of the slice, in order,
+// where start is the index of seg[0] in the slice. It avoids the per-element index math for hot loops.
+// seg holds the stored values (see SetCodec) and must not be modified or retained.
+// If fn returns true, it breaks early and returns true otherwise returns false.
+// Example:
+// 	ss.ForEachSegment(func(start int, seg []interface{}) bool {
+// 		for _, v := range seg {
+// 			sum += v.(int)
+// 		}
+// 		return false
+// 	})
+func (ss *Slice) ForEachSegment(fn func(start int, seg []interface{}) (breakNow bool)) bool {
+	mods := ss.mods
+	return ss.forEachSeg(0, ss.len, func(off int, seg []interface{}) bool {
+		breakNow := fn(off, seg)
+		ss.checkMods(mods)
+		return breakNow
+	})
+}
+
+// ForEachReverse loops over the slice from the last element to the first and calls fn for each element.
+// If fn returns true, it breaks early and returns true otherwise returns false.
+func (ss *Slice) ForEachReverse(fn func(i int, v interface{}) (breakNow bool)) bool {
+	mods := ss.mods
+	for i := ss.len - 1; i >= 0; {
+		di, si := ss.index(ss.baseIdx + i)
+		for seg := ss.data[di]; si >= 0 && i >= 0; si, i = si-1, i-1 {
+			if fn(i, ss.load(seg[si])) {
+				return true
+			}
+			ss.checkMods(mods)
+			seg = ss.data[di] // fn may have replaced a shared segment, see own
+		}
+	}
+	return false
+}
+
 // ForEach is an alias for ForEachAt(0, fn).
 func (ss *Slice) ForEach(fn func(i int, v interface{}) (breakNow bool)) bool {
 	return ss.ForEachAt(0, fn)
 }
 
-// IterAt returns an Iterator object
+// IterAt returns an Iterator object, it panics if [start, end) isn't a valid range of the slice.
 // Example:
 // 	for it := ss.IterAt(0, ss.Len()); it.More(); {
 // 		log.Println(it.Next())
 // 	}
 func (ss *Slice) IterAt(start, end int) *Iterator {
-	return &Iterator{
-		ss:    ss,
-		start: start,
-		end:   end,
-	}
+	it := NewIterVal(ss, start, end)
+	return &it
 }
 
 // Iter is an alias for IterAt(0, ss.Len()).
 func (ss *Slice) Iter() *Iterator { return ss.IterAt(0, ss.Len()) }
 
+// IterReverse returns an Iterator that goes from the last element to the first.
+func (ss *Slice) IterReverse() *Iterator {
+	return &Iterator{
+		ss:      ss,
+		end:     ss.len,
+		hi:      ss.len,
+		reverse: true,
+		mods:    ss.mods,
+	}
+}
+
+// IterOrdered returns an Iterator that yields the elements in the order of the indices in perm (e.g. a sorted permutation),
+// without reordering the data. NextIndex returns the index of the element in the slice.
+func (ss *Slice) IterOrdered(perm []int) *Iterator {
+	return &Iterator{
+		ss:   ss,
+		perm: perm,
+		end:  len(perm),
+		hi:   len(perm),
+		mods: ss.mods,
+	}
+}
+
+// SplitIter returns n iterators over disjoint contiguous ranges that together cover the slice,
+// the ranges are split on segment boundaries and hold roughly the same number of segments,
+// so they can be handed to n workers. If there are fewer segments than n, the extra iterators are empty.
+func (ss *Slice) SplitIter(n int) []*Iterator {
+	if n < 1 {
+		panic("n must be > 0")
+	}
+
+	var (
+		its  = make([]*Iterator, n)
+		segs int
+		seg  int
+	)
+
+	first, _ := ss.index(ss.baseIdx)
+	if ss.len > 0 {
+		last, _ := ss.index(ss.baseIdx + ss.len - 1)
+		segs = last - first + 1
+	}
+
+	start := 0
+	for i := range its {
+		cnt := segs / n
+		if i < segs%n {
+			cnt++
+		}
+		seg += cnt
+
+		end := ss.segStart(first+seg) - ss.baseIdx
+		if end > ss.len || i == n-1 {
+			end = ss.len
+		}
+		if end < start {
+			end = start
+		}
+		it := NewIterVal(ss, start, end)
+		its[i], start = &it, end
+	}
+
+	return its
+}
+
 // Slice returns a sub-slice, the equivalent of ss[start:end], modifying any data in the returned slice modifies the parent.
 func (ss *Slice) Slice(start, end int) *Slice {
 	cp := *ss
-	cp.len, cp.baseIdx = end-start, start
+	cp.len, cp.baseIdx = end-start, ss.baseIdx+start
+	cp.searchIdx = nil
+	cp.view = true
 	return &cp
 }
 
+// First returns the first element of the slice and true, or nil and false if the slice is empty.
+func (ss *Slice) First() (v interface{}, ok bool) {
+	if ss.len == 0 {
+		return nil, false
+	}
+	return ss.load(ss.GetUnchecked(0)), true
+}
+
+// Last returns the last element of the slice and true, or nil and false if the slice is empty.
+func (ss *Slice) Last() (v interface{}, ok bool) {
+	if ss.len == 0 {
+		return nil, false
+	}
+	return ss.load(ss.GetUnchecked(ss.len - 1)), true
+}
+
+// At is like Get but a negative i counts from the end of the slice, At(-1) returns the last element.
+func (ss *Slice) At(i int) interface{} { return ss.Get(ss.fromEnd(i)) }
+
+// SetAt is like Set but a negative i counts from the end of the slice, SetAt(-1, v) sets the last element.
+func (ss *Slice) SetAt(i int, v interface{}) { ss.Set(ss.fromEnd(i), v) }
+
+// SliceAt is like Slice but negative indices count from the end of the slice, SliceAt(-3, -1) returns
+// the third and second to last elements, the same as ss[-3:-1] in Python.
+// It panics if the resulting range isn't valid.
+func (ss *Slice) SliceAt(start, end int) *Slice {
+	start, end = ss.fromEnd(start), ss.fromEnd(end)
+	ss.checkRange(start, end)
+	return ss.Slice(start, end)
+}
+
+// fromEnd converts a negative index to one counting from the end of the slice.
+func (ss *Slice) fromEnd(i int) int {
+	if i < 0 {
+		return ss.len + i
+	}
+	return i
+}
+
 // Copy returns an exact copy of the slice that could be used independently.
 // Copy is internally used if you call Append, Pop or Grow on a sub-slice.
 func (ss *Slice) Copy() *Slice {
-	nss := NewSortable(ss.segLen+1, ss.lessFn)
+	nss := ss.newEmpty()
 	nss.Grow(ss.len)
-	nss.typ, nss.len, nss.shift = ss.typ, ss.len, ss.shift
-	ss.ForEach(func(i int, v interface{}) (_ bool) {
-		nss.Set(i, v)
+	nss.len = ss.len
+	nss.own(0, nss.len)
+	ss.forEachSegPair(nss, ss.len, func(_ int, src, dst []interface{}) (_ bool) {
+		copy(dst, src)
 		return
 	})
 	return nss
 }
 
 // Grow grows internal data structure to fit `sz` amount of new items.
+// When more than one segment is needed, they are all allocated at once, so the memory of a released segment
+// (see Truncate and Compact) is only freed once all the segments of the same Grow call are released.
 // If used on a sub-slice, it turns into an independent slice.
 func (ss *Slice) Grow(sz int) int {
-	if ss.baseIdx != 0 {
+	ss.checkFrozen()
+	if ss.view || ss.baseIdx != 0 {
+		if ss.metrics != nil {
+			ss.metrics.Copied(ss.len)
+		}
 		cp := ss.Copy()
+		cp.mods = ss.mods + 1
 		*ss = *cp
 	}
 
@@ -163,22 +455,47 @@ func (ss *Slice) Grow(sz int) int {
 		return 0
 	}
 
-	if ss.segLen < 1 {
+	if !ss.sized {
 		ss.segLen = DefaultSegmentLen - 1
 		ss.shift = findShift(DefaultSegmentLen)
+		ss.sized = true
 	}
 
-	if sz = ss.len + sz; sz <= ss.cap {
-		return 0
+	// the capacity is rounded up to a whole segment, it must still fit in an int
+	if sz > maxInt-ss.len-ss.segLen-1 {
+		panic(fmt.Sprintf("slice length overflows int: %d + %d", ss.len, sz))
 	}
 
-	segLen := ss.segLen + 1
-	newSize := 1 + (sz-ss.cap)/segLen
+	newSize := ss.segmentsFor(sz)
+
+	// without a pool, all the new segments are windows of a single allocation
+	var buf []interface{}
+	if newSize > 1 && ss.pool == nil && !ss.lazy {
+		buf = make([]interface{}, ss.segStart(len(ss.data)+newSize)-ss.segStart(len(ss.data)))
+		ss.allocs++
+		if n := len(ss.data); n+newSize > cap(ss.data) {
+			ss.data = append(ss.data, make([][]interface{}, newSize)...)[:n]
+		}
+	}
 
 	for i := 0; i < newSize; i++ {
-		ss.data = append(ss.data, make([]interface{}, segLen))
+		segLen := ss.segSize(len(ss.data))
+		if buf != nil {
+			ss.data = append(ss.data, buf[:segLen:segLen])
+			buf = buf[segLen:]
+		} else if ss.lazy {
+			ss.data = append(ss.data, nil)
+		} else {
+			ss.data = append(ss.data, ss.newSegment(segLen))
+		}
+		if ss.shared != nil {
+			ss.shared = append(ss.shared, false)
+		}
 		ss.cap += segLen
 	}
+	if newSize > 0 && ss.metrics != nil {
+		ss.metrics.Grew(newSize)
+	}
 	//log.Println(sz, segLen, len(ss.data))
 	return newSize
 }
@@ -192,42 +509,82 @@ func (ss *Slice) Cap() int { return ss.cap }
 // Segments returns the number of segments.
 func (ss *Slice) Segments() int { return len(ss.data) }
 
+// SliceStats holds segment utilization stats of a Slice, see Stats.
+type SliceStats struct {
+	Len       int
+	Cap       int
+	Segments  int
+	Allocated int     // number of allocated segments, less than Segments with WithLazySegments
+	LastFill  float64 // fill ratio of the segment holding the last element, 0 if the slice is empty
+	Wasted    int     // number of unused elements past the end of the slice
+	BaseIdx   int     // offset of a sub-slice in its parent's segments
+	Allocs    int     // number of segment allocations (or batches, see Grow) since the slice was created
+}
+
+// Stats returns segment utilization stats of the slice, they can be used to tune segLen (see also TuneSegLen).
+func (ss *Slice) Stats() (st SliceStats) {
+	st = SliceStats{
+		Len:       ss.len,
+		Cap:       ss.cap,
+		Segments:  len(ss.data),
+		Allocated: ss.allocated(),
+		Wasted:    ss.cap - ss.baseIdx - ss.len,
+		BaseIdx:   ss.baseIdx,
+		Allocs:    ss.allocs,
+	}
+
+	if ss.len > 0 {
+		di, si := ss.index(ss.baseIdx + ss.len - 1)
+		st.LastFill = float64(si+1) / float64(ss.segSize(di))
+	}
+	return
+}
+
 // Less adds support for sort.Interface
 func (ss *Slice) Less(i, j int) bool { return ss.lessFn(ss.Get(i), ss.Get(j)) }
 
 // Swap adds support for sort.Interface
 func (ss *Slice) Swap(i, j int) {
-	a, b := ss.ptrAt(i), ss.ptrAt(j)
+	ss.own(i, i+1)
+	ss.own(j, j+1)
+	a, b := ss.ptrAt(ss.baseIdx+i), ss.ptrAt(ss.baseIdx+j)
 	*a, *b = *b, *a
 }
 
 // MarshalJSON implements json.Marshaler
 func (ss *Slice) MarshalJSON() ([]byte, error) {
+	return ss.AppendJSON(make([]byte, 0, 2+(6*ss.Len())))
+}
+
+// AppendJSON appends the JSON encoding of the slice to dst and returns the extended buffer,
+// so the same buffer can be reused across calls. On error, dst is returned unmodified.
+// Elements of type int, int64, float64, string and bool are encoded without going through encoding/json.
+func (ss *Slice) AppendJSON(dst []byte) ([]byte, error) {
 	if ss.Len() == 0 {
-		return []byte("[]"), nil
+		return append(dst, "[]"...), nil
 	}
 
 	var (
-		b   = bytes.NewBuffer(make([]byte, 0, 2+(6*ss.Len())))
-		enc = json.NewEncoder(b)
+		b   = append(dst, '[')
+		err error
 		it  = ss.Iter()
 	)
 
-	b.WriteByte('[')
 	for {
-		enc.Encode(it.Next())
+		if b, err = appendJSONValue(b, it.Next()); err != nil {
+			return dst, err
+		}
 		if !it.More() {
 			break
 		}
-		b.WriteString(",")
+		b = append(b, ',')
 	}
 
-	b.WriteByte(']')
-
-	return b.Bytes(), nil
+	return append(b, ']'), nil
 }
 
 // SetUnmarshalType sets the internal type used for UnmarshalJSON.
+// Elements are decoded as the exact type passed (T or *T), unless the slice was created WithElemMode.
 // Example:
 // 	ss.SetUnmarshalType(&DataStruct{})
 // 	ss.SetUnmarshalType(reflect.TypeOf(&DataStruct{}))
@@ -244,8 +601,58 @@ func (ss *Slice) SetUnmarshalType(val interface{}) {
 	}
 }
 
+// UnmarshalOptions controls the behaviour of UnmarshalJSON.
+// The limits control how much memory UnmarshalJSON is allowed to use, a zero value means no limit.
+type UnmarshalOptions struct {
+	// Append makes UnmarshalJSON append to the existing elements rather than replacing them.
+	Append bool
+
+	// MaxElements is the max number of elements decoded per call.
+	MaxElements int
+	// MaxDepth is the max nesting depth of each element, scalars have a depth of 0 and `[]` or `{}` have a depth of 1.
+	MaxDepth int
+	// MaxBytes is the max size of the JSON input.
+	MaxBytes int
+
+	// SkipInvalid makes UnmarshalJSON skip the elements that can't be decoded into the unmarshal type (or are too deep),
+	// rather than stopping at the first one. The skipped elements are reported in a DecodeErrors once the rest are loaded.
+	// Malformed JSON still stops the decoding.
+	SkipInvalid bool
+}
+
+// ElemError is the error for a single element that failed to decode.
+type ElemError struct {
+	// Index is the position of the element in the JSON array.
+	Index int
+	Err   error
+}
+
+func (e *ElemError) Error() string { return fmt.Sprintf("element %d: %v", e.Index, e.Err) }
+
+// DecodeErrors is returned by UnmarshalJSON when UnmarshalOptions.SkipInvalid is set and some elements were skipped.
+type DecodeErrors []*ElemError
+
+func (e DecodeErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%d elements failed to decode, first: %v", len(e), e[0])
+}
+
+// SetUnmarshalOptions sets the options used by UnmarshalJSON.
+func (ss *Slice) SetUnmarshalOptions(opts UnmarshalOptions) {
+	ss.uopts = opts
+}
+
 // UnmarshalJSON implements json.Unmarshaler
+// The elements are only added to the slice once the whole input is decoded, so an error leaves the slice unchanged
+// (except for the elements skipped with SkipInvalid, which are reported in a DecodeErrors).
 func (ss *Slice) UnmarshalJSON(b []byte) (err error) {
+	opts := &ss.uopts
+	if opts.MaxBytes > 0 && len(b) > opts.MaxBytes {
+		return fmt.Errorf("input too large: %d bytes, max: %d", len(b), opts.MaxBytes)
+	}
+
 	var (
 		dec = json.NewDecoder(bytes.NewReader(b))
 		t   json.Token
@@ -259,22 +666,24 @@ func (ss *Slice) UnmarshalJSON(b []byte) (err error) {
 		return fmt.Errorf("expected '[', got: %v (%T)", t, t)
 	}
 
-	if ss.typ != nil {
-		for dec.More() {
-			v := reflect.New(ss.typ)
-			if err = dec.Decode(v.Interface()); err != nil {
-				return
-			}
-			ss.Append(v.Elem().Interface())
+	var (
+		vals []interface{}
+		errs DecodeErrors
+	)
+	for n := 0; dec.More(); n++ {
+		if opts.MaxElements > 0 && n == opts.MaxElements {
+			return fmt.Errorf("too many elements, max: %d", opts.MaxElements)
 		}
-	} else {
-		for dec.More() {
-			var v interface{}
-			if err = dec.Decode(&v); err != nil {
-				return
-			}
-			ss.Append(v)
+
+		v, invalid, err := ss.decodeElem(dec)
+		if invalid && opts.SkipInvalid {
+			errs = append(errs, &ElemError{Index: n, Err: err})
+			continue
+		}
+		if err != nil {
+			return err
 		}
+		vals = append(vals, v)
 	}
 
 	if t, err = dec.Token(); err != nil {
@@ -285,9 +694,54 @@ func (ss *Slice) UnmarshalJSON(b []byte) (err error) {
 		return fmt.Errorf("expected ']', got: %v (%T)", t, t)
 	}
 
+	if !opts.Append {
+		ss.Reset()
+	}
+	ss.AppendSlice(vals)
+
+	if errs != nil {
+		return errs
+	}
 	return nil
 }
 
+// decodeElem decodes the next element from dec into the type set by SetUnmarshalType.
+// invalid is true if the element is valid JSON that couldn't be decoded, so the decoder can move on to the next element.
+func (ss *Slice) decodeElem(dec *json.Decoder) (v interface{}, invalid bool, err error) {
+	var (
+		dst interface{} = &v
+		rv  reflect.Value
+	)
+
+	if ss.typ != nil {
+		rv = reflect.New(ss.typ)
+		dst = rv.Interface()
+	}
+
+	if maxDepth := ss.uopts.MaxDepth; maxDepth > 0 || ss.uopts.SkipInvalid {
+		var raw json.RawMessage
+		if err = dec.Decode(&raw); err != nil {
+			return
+		}
+		if d := jsonDepth(raw); maxDepth > 0 && d > maxDepth {
+			return nil, true, fmt.Errorf("element too deep: %d, max: %d", d, maxDepth)
+		}
+		err = json.Unmarshal(raw, dst)
+		invalid = err != nil
+	} else {
+		err = dec.Decode(dst)
+	}
+
+	if err != nil || ss.typ == nil {
+		return
+	}
+
+	if ss.mode == ElemByPointer && ss.typ.Kind() != reflect.Ptr {
+		return rv.Interface(), false, nil
+	}
+	return rv.Elem().Interface(), false, nil
+}
+
 // String implements fmt.Stringer
 func (ss *Slice) String() string {
 	var (
@@ -317,6 +771,9 @@ func (ss *Slice) GoString() string {
 
 // index returns the internal data index and slice index for an index
 func (ss *Slice) index(i int) (int, int) {
+	if ss.geo != nil {
+		return ss.geoIndex(i)
+	}
 	return i >> ss.shift, i & ss.segLen
 }
 
@@ -326,6 +783,205 @@ func (ss *Slice) ptrAt(i int) *interface{} {
 	return &ss.data[di][si]
 }
 
+// newEmpty returns an empty slice with the same segment length, lessFn, unmarshal settings, ElemMode and codec as ss.
+func (ss *Slice) newEmpty() *Slice {
+	return &Slice{
+		segLen: ss.segLen,
+		shift:  ss.shift,
+		sized:  ss.sized,
+		geo:    ss.geo,
+		lazy:   ss.lazy,
+		lessFn: ss.lessFn,
+		typ:    ss.typ,
+		uopts:  ss.uopts,
+		mode:   ss.mode,
+		enc:    ss.enc,
+		dec:    ss.dec,
+		pool:   ss.pool,
+
+		metrics: ss.metrics,
+	}
+}
+
+// segmentsFor returns the number of segments Grow allocates to fit sz more items.
+func (ss *Slice) segmentsFor(sz int) int {
+	if sz = ss.len + sz; sz <= ss.cap {
+		return 0
+	}
+	if ss.geo == nil {
+		return 1 + (sz-ss.cap)/(ss.segLen+1)
+	}
+
+	n := 0
+	for c := ss.cap; c < sz; n++ {
+		c += ss.segSize(len(ss.data) + n)
+	}
+	return n
+}
+
+// newSegment returns an empty segment, from the pool if the slice has one.
+func (ss *Slice) newSegment(segLen int) []interface{} {
+	ss.allocs++
+	if ss.pool != nil {
+		return ss.pool.get(segLen)
+	}
+	return make([]interface{}, segLen)
+}
+
+// clearRange sets the elements in [start, end) to nil.
+func (ss *Slice) clearRange(start, end int) {
+	ss.own(start, end)
+	ss.forEachSeg(start, end, func(_ int, seg []interface{}) (_ bool) {
+		for i := range seg {
+			seg[i] = nil
+		}
+		return
+	})
+}
+
+// retain keeps the elements fn returns true for, in order, and returns the number of removed elements.
+// If used on a sub-slice, it turns into an independent slice.
+func (ss *Slice) retain(fn func(v interface{}) bool) int {
+	return ss.retainStored(func(v interface{}) bool { return fn(ss.load(v)) })
+}
+
+// retainStored is like retain but calls fn with the stored values.
+func (ss *Slice) retainStored(fn func(v interface{}) bool) int {
+	ss.Grow(0)
+	ss.own(0, ss.len)
+	n := 0
+	ss.forEachSeg(0, ss.len, func(_ int, seg []interface{}) (_ bool) {
+		for _, v := range seg {
+			if fn(v) {
+				*ss.ptrAt(n) = v
+				n++
+			}
+		}
+		return
+	})
+
+	removed := ss.len - n
+	ss.clearRange(n, ss.len)
+	ss.len = n
+	ss.mods++
+	return removed
+}
+
+// insert inserts v at index i, shifting the following elements.
+func (ss *Slice) insert(i int, v interface{}) {
+	ss.Grow(1)
+	ss.len++
+	ss.mods++
+	ss.own(i, ss.len)
+	ss.move(i+1, i, ss.len-1-i)
+	*ss.ptrAt(ss.baseIdx + i) = ss.store(v)
+}
+
+// remove deletes the element at index i, shifting the following elements.
+// If used on a sub-slice, it turns into an independent slice.
+func (ss *Slice) remove(i int) {
+	ss.Grow(0)
+	ss.move(i, i+1, ss.len-1-i)
+	ss.Pop()
+}
+
+// move copies n elements from src to dst segment by segment, the ranges may overlap.
+func (ss *Slice) move(dst, src, n int) {
+	ss.own(dst, dst+n)
+	if dst == src {
+		return
+	}
+
+	if dst < src {
+		for n > 0 {
+			ddi, dsi := ss.index(ss.baseIdx + dst)
+			sdi, ssi := ss.index(ss.baseIdx + src)
+			s := ss.data[sdi][ssi:]
+			if len(s) > n {
+				s = s[:n]
+			}
+			k := copy(ss.data[ddi][dsi:], s)
+			dst, src, n = dst+k, src+k, n-k
+		}
+		return
+	}
+
+	for n > 0 {
+		ddi, dsi := ss.index(ss.baseIdx + dst + n - 1)
+		sdi, ssi := ss.index(ss.baseIdx + src + n - 1)
+		k := dsi
+		if ssi < k {
+			k = ssi
+		}
+		if k++; k > n {
+			k = n
+		}
+		copy(ss.data[ddi][dsi+1-k:dsi+1], ss.data[sdi][ssi+1-k:ssi+1])
+		n -= k
+	}
+}
+
+// checkIndex panics if i isn't a valid index of the slice.
+func (ss *Slice) checkIndex(i int) {
+	if uint(i) >= uint(ss.len) {
+		panic(ErrIndexOutOfRange{i, ss.len}.Error())
+	}
+}
+
+// checkMods panics if the length of the slice changed since mods was taken.
+func (ss *Slice) checkMods(mods uint) {
+	if ss.mods != mods {
+		panic("slice was modified during iteration")
+	}
+}
+
+// checkRange panics if [start, end) isn't a valid range of the slice.
+func (ss *Slice) checkRange(start, end int) {
+	if start < 0 || start > end || end > ss.len {
+		panic(fmt.Sprintf("invalid range [%d:%d] with length %d", start, end, ss.len))
+	}
+}
+
+// forEachSeg calls fn with the part of each backing segment that covers [start, end),
+// off is the index of seg[0] relative to the slice.
+func (ss *Slice) forEachSeg(start, end int, fn func(off int, seg []interface{}) (breakNow bool)) bool {
+	for start < end {
+		di, si := ss.index(ss.baseIdx + start)
+		seg := ss.data[di][si:]
+		if n := end - start; len(seg) > n {
+			seg = seg[:n]
+		}
+		if fn(start, seg) {
+			return true
+		}
+		start += len(seg)
+	}
+	return false
+}
+
+// jsonDepth returns the max nesting depth of a valid JSON value.
+func jsonDepth(b []byte) (max int) {
+	var depth int
+	var inStr, esc bool
+	for _, c := range b {
+		switch {
+		case esc:
+			esc = false
+		case inStr:
+			esc, inStr = c == '\\', c != '"'
+		case c == '"':
+			inStr = true
+		case c == '[' || c == '{':
+			if depth++; depth > max {
+				max = depth
+			}
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return
+}
+
 func isPowerOfTwo(n int) bool {
 	return n > 0 && n&(n-1) == 0
 }
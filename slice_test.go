@@ -3,9 +3,17 @@ package segmentedSlice
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"hash"
+	"io"
+	"math"
 	"math/rand"
 	"sort"
+	"strconv"
 	"testing"
+	"time"
 )
 
 var sink interface{}
@@ -46,6 +54,1856 @@ func TestSegmentedSlice(t *testing.T) {
 	})
 }
 
+func TestGetSet(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 10; i++ {
+		l.Append(i)
+	}
+	sub := l.Slice(2, 6)
+
+	sub.Set(1, 42)
+	if sub.Get(1).(int) != 42 || l.Get(3).(int) != 42 || l.GetUnchecked(3).(int) != 42 {
+		t.Fatalf("unexpected values: %v %v", sub, l)
+	}
+
+	sub.SetUnchecked(5, 43) // past the end of sub, but still in l
+	if l.Get(7).(int) != 43 || sub.GetUnchecked(5).(int) != 43 {
+		t.Fatalf("unexpected values: %v %v", sub, l)
+	}
+
+	for _, i := range []int{-1, 4, 10} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for %d", i)
+				}
+			}()
+			sub.Get(i)
+		}()
+	}
+}
+
+func TestSeqReader(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 20; i++ {
+		l.Append(i)
+	}
+
+	r := l.Slice(3, 17).SeqReader()
+	for _, i := range []int{0, 1, 2, 3, 4, 13, 5, 0} {
+		if r.Get(i).(int) != i+3 {
+			t.Fatalf("expected %d at %d, got %v", i+3, i, r.Get(i))
+		}
+	}
+
+	r.Set(5, 42)
+	if l.Get(8).(int) != 42 {
+		t.Fatalf("expected 42, got %v", l.Get(8))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	r.Get(14)
+}
+
+func TestIterAt(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 10; i++ {
+		l.Append(i)
+	}
+
+	it := l.IterAt(2, 7)
+	for exp := 5; it.More(); exp-- {
+		if it.Len() != exp {
+			t.Fatalf("expected %d items left, got %d", exp, it.Len())
+		}
+		it.Next()
+	}
+	if it.Len() != 0 {
+		t.Fatalf("expected an empty iterator, got %d", it.Len())
+	}
+
+	for _, r := range [][2]int{{-1, 5}, {5, 4}, {0, 11}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for %v", r)
+				}
+			}()
+			l.IterAt(r[0], r[1])
+		}()
+	}
+}
+
+func TestForEachSegment(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 15; i++ {
+		l.Append(i)
+	}
+
+	var starts, lens []int
+	l.Slice(2, 14).ForEachSegment(func(start int, seg []interface{}) bool {
+		for i, v := range seg {
+			if v != start+i+2 {
+				t.Fatalf("expected %d, got %v", start+i+2, v)
+			}
+		}
+		starts, lens = append(starts, start), append(lens, len(seg))
+		return false
+	})
+
+	if fmt.Sprint(starts, lens) != "[0 2 6 10] [2 4 4 2]" {
+		t.Fatalf("unexpected segments: %v %v", starts, lens)
+	}
+
+	n := 0
+	if !l.ForEachSegment(func(int, []interface{}) bool { n++; return true }) || n != 1 {
+		t.Fatal("expected ForEachSegment to stop")
+	}
+}
+
+func TestReverseIteration(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 11; i++ {
+		l.Append(i)
+	}
+	sub := l.Slice(1, 10)
+
+	exp := sub.Len() - 1
+	for it := sub.IterReverse(); it.More(); exp-- {
+		if i, v := it.NextIndex(); i != exp || v != exp+1 {
+			t.Fatalf("IterReverse: unexpected pair %d %v", i, v)
+		}
+	}
+	if exp != -1 {
+		t.Fatalf("IterReverse stopped at %d", exp)
+	}
+
+	var got []interface{}
+	stopped := sub.ForEachReverse(func(i int, v interface{}) bool {
+		got = append(got, v)
+		return i == 5
+	})
+	if !stopped || fmt.Sprint(got) != "[9 8 7 6]" {
+		t.Fatalf("ForEachReverse: unexpected result %v %v", stopped, got)
+	}
+}
+
+func TestAcquireIter(t *testing.T) {
+	l := sliceOf(1, 2, 3, 4, 5)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		it := AcquireIter(l, 1, 4)
+		for it.More() {
+			sink = it.Next()
+		}
+		it.Release()
+	})
+	if allocs >= 1 { // the pool may drop some items, e.g. during a GC
+		t.Fatalf("expected no allocations, got %v", allocs)
+	}
+
+	it := AcquireIter(l, 1, 4)
+	if it.Len() != 3 || it.Next() != 2 {
+		t.Fatalf("unexpected iterator: %+v", it)
+	}
+	it.Release()
+}
+
+func TestIterPeek(t *testing.T) {
+	l := sliceOf(1, 2, 3)
+
+	for _, it := range []*Iterator{l.Iter(), l.IterReverse()} {
+		var got []interface{}
+		for it.More() {
+			v := it.Peek()
+			if it.Peek() != v || it.Next() != v {
+				t.Fatalf("Peek and Next disagree at %v", v)
+			}
+			got = append(got, v)
+		}
+		if len(got) != 3 {
+			t.Fatalf("unexpected values: %v", got)
+		}
+	}
+
+	it := l.IterOrdered([]int{2, 0, 1})
+	if i, v := it.PeekIndex(); i != 2 || v != 3 || it.Len() != 3 {
+		t.Fatalf("unexpected PeekIndex: %d %v", i, v)
+	}
+}
+
+func TestChainIter(t *testing.T) {
+	a, b := sliceOf(0, 1, 2, 3, 4, 5), sliceOf(6, 7)
+	it := ChainIter(a.Slice(0, 5), New(4), b, New(4))
+
+	if it.Len() != 7 {
+		t.Fatalf("expected 7 items, got %d", it.Len())
+	}
+
+	var idxs, vals []interface{}
+	for it.More() {
+		i, v := it.NextIndex()
+		idxs, vals = append(idxs, i), append(vals, v)
+	}
+	if fmt.Sprint(idxs, vals) != "[0 1 2 3 4 5 6] [0 1 2 3 4 6 7]" || it.Len() != 0 {
+		t.Fatalf("unexpected items: %v %v", idxs, vals)
+	}
+
+	it = ChainIter(a, b)
+	for i := 0; i < 7; i++ {
+		it.Next()
+	}
+	if src, idx, v := it.NextSource(); src != 1 || idx != 1 || v != 7 {
+		t.Fatalf("unexpected source: %d %d %v", src, idx, v)
+	}
+}
+
+func TestIterSeek(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 11; i++ {
+		l.Append(i)
+	}
+
+	for _, tc := range []struct {
+		it   *Iterator
+		seek int
+		exp  string
+	}{
+		{l.IterAt(2, 9), 4, "[6 7 8]"},
+		{l.IterReverse(), 8, "[2 1 0]"},
+		{l.IterOrdered([]int{5, 1, 3, 0}), 2, "[3 0]"},
+		{l.IterAt(2, 9), 7, "[]"},
+	} {
+		for tc.it.More() {
+			tc.it.Next()
+		}
+
+		tc.it.Reset()
+		all := tc.it.Remaining()
+		tc.it.Seek(tc.seek)
+		if tc.it.Remaining() != all-tc.seek {
+			t.Fatalf("expected %d remaining, got %d", all-tc.seek, tc.it.Remaining())
+		}
+
+		got := []interface{}{}
+		for tc.it.More() {
+			got = append(got, tc.it.Next())
+		}
+		if fmt.Sprint(got) != tc.exp {
+			t.Fatalf("expected %s, got %v", tc.exp, got)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	l.Iter().Seek(12)
+}
+
+func TestIterPastEnd(t *testing.T) {
+	l := sliceOf(1, 2, 3)
+	l.Grow(5) // spare capacity must not be returned
+
+	for _, it := range []*Iterator{l.Slice(0, 2).Iter(), l.IterReverse()} {
+		for it.More() {
+			it.Next()
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != "no more items" {
+					t.Fatalf("expected a panic, got %v", r)
+				}
+			}()
+			it.Next()
+		}()
+	}
+}
+
+func TestIterModified(t *testing.T) {
+	l := sliceOf(1, 2, 3, 4, 5)
+
+	for name, fn := range map[string]func(){
+		"Iterator": func() {
+			it := l.Iter()
+			it.Next()
+			l.Pop()
+			it.Next()
+		},
+		"ForEach": func() {
+			l.ForEach(func(i int, v interface{}) (_ bool) {
+				if i == 1 {
+					l.Append(v)
+				}
+				return
+			})
+		},
+		"ForEachReverse": func() {
+			l.ForEachReverse(func(i int, v interface{}) (_ bool) {
+				l.Pop()
+				return
+			})
+		},
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != "slice was modified during iteration" {
+					t.Errorf("%s: expected a panic, got %v", name, r)
+				}
+			}()
+			fn()
+		}()
+	}
+
+	l = sliceOf(1, 2, 3)
+	l.ForEach(func(i int, v interface{}) (_ bool) {
+		l.Set(i, v.(int)*2)
+		return
+	})
+	if exp := "[2, 4, 6]"; l.String() != exp {
+		t.Fatalf("expected %s, got %v", exp, l)
+	}
+}
+
+func TestIterOrdered(t *testing.T) {
+	l := sliceOf("c", "a", "d", "b")
+	perm := []int{1, 3, 0, 2}
+
+	var got []interface{}
+	for it := l.IterOrdered(perm); it.More(); {
+		idx, v := it.NextIndex()
+		if l.Get(idx) != v {
+			t.Fatalf("expected index %d to hold %v", idx, v)
+		}
+		got = append(got, v)
+	}
+
+	if exp := "[a b c d]"; fmt.Sprint(got) != exp {
+		t.Fatalf("expected %s, got %v", exp, got)
+	}
+	if exp := "[c, a, d, b]"; l.String() != exp {
+		t.Fatalf("the data was reordered: %v", l)
+	}
+}
+
+func TestSetOps(t *testing.T) {
+	a, b := sliceOf(1, 2, 2, 3, 4), sliceOf(4, 5, 3, 6, 5)
+	hash := func(v interface{}) string { return fmt.Sprint(v) }
+
+	for _, c := range []struct {
+		name string
+		got  *Slice
+		exp  string
+	}{
+		{"union", a.Union(b, hash), "[1, 2, 3, 4, 5, 6]"},
+		{"intersect", a.Intersect(b, hash), "[3, 4]"},
+		{"difference", a.Difference(b, hash), "[1, 2]"},
+		{"difference rev", b.Difference(a, hash), "[5, 6]"},
+	} {
+		if c.got.String() != c.exp {
+			t.Errorf("%s: expected %s, got %v", c.name, c.exp, c.got)
+		}
+	}
+
+	if exp := "[1, 2, 2, 3, 4]"; a.String() != exp {
+		t.Fatalf("a was modified: %v", a)
+	}
+}
+
+func TestIndexSorter(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 30; i++ {
+		l.Append(rand.Intn(100))
+	}
+
+	sub := l.Slice(3, 27)
+	is := sub.IndexSorter(func(a, b interface{}) bool { return a.(int) < b.(int) })
+	sort.Stable(is)
+
+	for i := 1; i < sub.Len(); i++ {
+		if is.Compare(i-1, i) > 0 {
+			t.Fatalf("not sorted at %d: %v", i, sub)
+		}
+	}
+	if is.Compare(0, 0) != 0 || is.Compare(sub.Len()-1, 0) != 1 && sub.Get(0) != sub.Get(sub.Len()-1) {
+		t.Fatalf("unexpected Compare results: %v", sub)
+	}
+}
+
+func TestSplitIter(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 30; i++ {
+		l.Append(i)
+	}
+
+	for _, tc := range []struct {
+		ss   *Slice
+		n    int
+		lens []int
+	}{
+		{l, 3, []int{12, 12, 6}},
+		{l, 1, []int{30}},
+		{l.Slice(2, 21), 2, []int{10, 9}},
+		{l.Slice(2, 7), 4, []int{2, 3, 0, 0}},
+		{l.Slice(0, 0), 2, []int{0, 0}},
+	} {
+		its := tc.ss.SplitIter(tc.n)
+		next := tc.ss.baseIdx
+		for i, it := range its {
+			if it.Len() != tc.lens[i] {
+				t.Fatalf("%d: expected lengths %v, got %d at %d", tc.n, tc.lens, it.Len(), i)
+			}
+			for it.More() {
+				if v := it.Next(); v != next {
+					t.Fatalf("expected %d, got %v", next, v)
+				}
+				next++
+			}
+		}
+		if next != tc.ss.baseIdx+tc.ss.Len() {
+			t.Fatalf("not all the elements were covered: %d", next)
+		}
+	}
+}
+
+func TestTuneSegLen(t *testing.T) {
+	r := TuneSegLen(func(ss *Slice) {
+		for i := 0; i < 1000; i++ {
+			ss.Append(rand.Int())
+		}
+	}, []int{4, 64, 1024}, WithLess(func(a, b interface{}) bool { return a.(int) < b.(int) }))
+
+	if len(r.Results) != 3 || r.Best == 0 {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+	var best SegLenResult
+	for _, res := range r.Results {
+		if res.Len != 1000 || res.Sort == 0 {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+		if res.SegLen == r.Best {
+			best = res
+		}
+	}
+	for _, res := range r.Results {
+		if res.Total() < best.Total() {
+			t.Fatalf("%d isn't the best: %v", r.Best, r)
+		}
+	}
+	if r.Results[0].Segments != 250 {
+		t.Fatalf("expected 250 segments, got %d", r.Results[0].Segments)
+	}
+}
+
+func TestMapView(t *testing.T) {
+	var calls int
+	l := sliceOf(1, 2, 3, 4)
+	v := l.Slice(1, 4).MapView(func(v interface{}) interface{} { calls++; return v.(int) * 10 })
+	if calls != 0 {
+		t.Fatal("MapView shouldn't call fn")
+	}
+
+	if v.Len() != 3 || v.Get(0) != 20 {
+		t.Fatalf("unexpected view: %d %v", v.Len(), v.Get(0))
+	}
+
+	var got []interface{}
+	for it := v.MapView(func(v interface{}) interface{} { return v.(int) + 1 }).Iter(); it.More(); {
+		got = append(got, it.Next())
+	}
+	if fmt.Sprint(got) != "[21 31 41]" {
+		t.Fatalf("unexpected values: %v", got)
+	}
+
+	l.Set(3, 5)
+	if exp := "[20, 30, 50]"; v.Materialize().String() != exp {
+		t.Fatalf("expected %s, got %v", exp, v.Materialize())
+	}
+	if exp := "[1, 2, 3, 5]"; l.String() != exp {
+		t.Fatalf("the slice was modified: %v", l)
+	}
+}
+
+func TestFilterView(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 20; i++ {
+		l.Append(i)
+	}
+	odd := func(v interface{}) bool { return v.(int)%2 == 1 }
+	fv := l.Slice(0, 11).FilterView(odd)
+
+	var idxs, vals []interface{}
+	for it := fv.Iter(); it.More(); {
+		i, v := it.NextIndex()
+		idxs, vals = append(idxs, i), append(vals, v)
+	}
+	if fmt.Sprint(idxs, vals) != "[1 3 5 7 9] [1 3 5 7 9]" {
+		t.Fatalf("unexpected items: %v %v", idxs, vals)
+	}
+
+	n := 0
+	fv.ForEach(func(i int, v interface{}) bool {
+		if !odd(v) {
+			t.Fatalf("unexpected item %v", v)
+		}
+		n++
+		return n == 3
+	})
+	if n != 3 || fv.Count() != 5 || fv.Materialize().Len() != 5 {
+		t.Fatalf("unexpected counts: %d %d", n, fv.Count())
+	}
+}
+
+func TestNewFromSlice(t *testing.T) {
+	src := make([]interface{}, 11)
+	for i := range src {
+		src[i] = i
+	}
+
+	l := NewFromSlice(4, src)
+	if l.Len() != 11 || l.Cap() != 12 || fmt.Sprint(l.ToSlice()) != fmt.Sprint(src) {
+		t.Fatalf("unexpected slice: %#v", l)
+	}
+
+	l.Set(0, -1)
+	if src[0] != 0 {
+		t.Fatal("src was modified")
+	}
+
+	p := NewFromSlice(4, []interface{}{point{1, 2}}, WithElemMode(ElemByPointer))
+	if _, ok := p.Get(0).(*point); !ok {
+		t.Fatalf("expected a *point, got %T", p.Get(0))
+	}
+}
+
+func TestAppendSlice(t *testing.T) {
+	l := sliceOf(0, 1, 2)
+	src := []interface{}{3, 4, 5, 6, 7, 8, 9}
+	l.AppendSlice(src)
+	l.AppendSlice(nil)
+	l.AppendSlice(src[:1])
+
+	if exp := "[0 1 2 3 4 5 6 7 8 9 3]"; fmt.Sprint(l.ToSlice()) != exp || l.Segments() != 3 {
+		t.Fatalf("expected %s, got %v", exp, l)
+	}
+
+	sub := l.Slice(1, 3)
+	sub.AppendSlice(src[:2])
+	if exp := "[1, 2, 3, 4]"; sub.String() != exp || l.Get(3) != 3 {
+		t.Fatalf("unexpected slices: %v %v", sub, l)
+	}
+}
+
+func TestCopyTo(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 11; i++ {
+		l.Append(i)
+	}
+
+	buf := make([]interface{}, 5)
+	if n := l.Slice(3, 11).CopyTo(buf); n != 5 || fmt.Sprint(buf) != "[3 4 5 6 7]" {
+		t.Fatalf("unexpected copy: %d %v", n, buf)
+	}
+	if n := l.Slice(9, 11).CopyTo(buf); n != 2 || fmt.Sprint(buf) != "[9 10 5 6 7]" {
+		t.Fatalf("unexpected copy: %d %v", n, buf)
+	}
+}
+
+func TestGetSetRange(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 11; i++ {
+		l.Append(i)
+	}
+
+	if got := l.GetRange(2, 9); fmt.Sprint(got) != "[2 3 4 5 6 7 8]" {
+		t.Fatalf("unexpected range: %v", got)
+	}
+
+	l.Slice(1, 11).SetRange(2, []interface{}{"a", "b", "c", "d", "e", "f"})
+	if exp := "[0, 1, 2, a, b, c, d, e, f, 9, 10]"; l.String() != exp {
+		t.Fatalf("expected %s, got %v", exp, l)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	l.SetRange(10, []interface{}{1, 2})
+}
+
+func TestCopyWithin(t *testing.T) {
+	for _, tc := range []struct {
+		dst, start, end int
+		exp             string
+	}{
+		{0, 3, 7, "[3 4 5 6 4 5 6 7 8 9 10]"},
+		{5, 0, 6, "[0 1 2 3 4 0 1 2 3 4 5]"},
+		{8, 0, 6, "[0 1 2 3 4 5 6 7 0 1 2]"},
+		{2, 1, 9, "[0 1 1 2 3 4 5 6 7 8 10]"},
+		{11, 0, 3, "[0 1 2 3 4 5 6 7 8 9 10]"},
+		{4, 4, 9, "[0 1 2 3 4 5 6 7 8 9 10]"},
+	} {
+		l := New(4)
+		for i := 0; i < 12; i++ {
+			l.Append(i - 1)
+		}
+		sub := l.Slice(1, 12)
+
+		sub.CopyWithin(tc.dst, tc.start, tc.end)
+		if got := fmt.Sprint(sub.ToSlice()); got != tc.exp {
+			t.Fatalf("%+v: expected %s, got %s", tc, tc.exp, got)
+		}
+	}
+}
+
+func TestFill(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 11; i++ {
+		l.Append(i)
+	}
+
+	l.Slice(1, 11).FillRange(2, 8, -1)
+	if exp := "[0, 1, 2, -1, -1, -1, -1, -1, -1, 9, 10]"; l.String() != exp {
+		t.Fatalf("expected %s, got %v", exp, l)
+	}
+	l.Fill(nil)
+	if l.CountFunc(func(v interface{}) bool { return v == nil }) != 11 {
+		t.Fatalf("expected all nils, got %v", l)
+	}
+
+	p := New(4, WithElemMode(ElemByPointer))
+	p.Append(nil, nil)
+	p.Fill(point{})
+	if p.Get(0).(*point) == p.Get(1).(*point) {
+		t.Fatal("expected different pointers")
+	}
+}
+
+func TestResize(t *testing.T) {
+	l := sliceOf(0, 1, 2)
+	l.Resize(9, -1)
+	if exp := "[0, 1, 2, -1, -1, -1, -1, -1, -1]"; l.String() != exp || l.Cap() != 12 {
+		t.Fatalf("expected %s, got %#v", exp, l)
+	}
+
+	l.Resize(2, nil)
+	if exp := "[0, 1]"; l.String() != exp || l.Cap() != 12 {
+		t.Fatalf("expected %s, got %#v", exp, l)
+	}
+	if l.data[0][2] != nil || l.data[1][0] != nil {
+		t.Fatal("removed elements weren't cleared")
+	}
+
+	l.Resize(2, nil)
+	l.Resize(0, nil)
+	if l.Len() != 0 {
+		t.Fatalf("expected an empty slice, got %v", l)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 15; i++ {
+		l.Append(i)
+	}
+
+	l.Truncate(10, false)
+	if l.Len() != 10 || l.Cap() != 16 || l.data[2][2] != nil {
+		t.Fatalf("unexpected slice: %#v", l)
+	}
+
+	l.Truncate(5, true)
+	if exp := "[0, 1, 2, 3, 4]"; l.String() != exp || l.Cap() != 8 || l.Segments() != 2 {
+		t.Fatalf("unexpected slice: %#v", l)
+	}
+
+	for _, start := range []int{0, 1} {
+		sub := l.Slice(start, 5)
+		sub.Truncate(2, true)
+		if sub.Len() != 2 || sub.Get(1) != start+1 || l.String() != "[0, 1, 2, 3, 4]" {
+			t.Fatalf("unexpected truncate of a sub-slice: %v %v", sub, l)
+		}
+	}
+
+	l.Truncate(0, true)
+	if l.Len() != 0 || l.Cap() != 0 {
+		t.Fatalf("unexpected slice: %#v", l)
+	}
+	l.Append(1)
+	if l.Get(0) != 1 || l.Cap() != 4 {
+		t.Fatalf("unexpected slice: %#v", l)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	l.Truncate(2, false)
+}
+
+func TestReset(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 10; i++ {
+		l.Append(i)
+	}
+	seg0 := &l.data[0][0]
+
+	l.Reset()
+	if l.Len() != 0 || l.Cap() != 12 || l.data[2][1] != nil {
+		t.Fatalf("unexpected slice: %#v", l)
+	}
+
+	l.Append(1, 2, 3)
+	if &l.data[0][0] != seg0 || l.Cap() != 12 {
+		t.Fatal("the segments weren't reused")
+	}
+
+	for _, start := range []int{0, 1} {
+		sub := l.Slice(start, 3)
+		sub.Reset()
+		if sub.Len() != 0 || sub.Cap() != 0 || l.String() != "[1, 2, 3]" {
+			t.Fatalf("unexpected slices: %#v %v", sub, l)
+		}
+		sub.Append(4)
+		if sub.String() != "[4]" || l.String() != "[1, 2, 3]" {
+			t.Fatalf("unexpected append after reset: %v %v", sub, l)
+		}
+	}
+}
+
+func TestReserve(t *testing.T) {
+	l := sliceOf(1, 2, 3)
+	l.Reserve(10)
+	if l.Cap() != 12 || l.Len() != 3 {
+		t.Fatalf("unexpected slice: %#v", l)
+	}
+	l.Reserve(5)
+	if l.Cap() != 12 {
+		t.Fatalf("unexpected slice: %#v", l)
+	}
+
+	sub := l.Slice(1, 3)
+	sub.Reserve(100)
+	if sub.baseIdx != 1 || sub.Cap() != 12 {
+		t.Fatalf("the sub-slice was copied: %#v", sub)
+	}
+
+	var z Slice
+	z.Reserve(200)
+	if z.Cap() != 2*DefaultSegmentLen {
+		t.Fatalf("unexpected slice: %#v", z)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 30; i++ {
+		l.Append(i)
+	}
+	for i := 0; i < 21; i++ {
+		l.Pop()
+	}
+
+	l.Compact()
+	if exp := "[0, 1, 2, 3, 4, 5, 6, 7, 8]"; l.String() != exp || l.Cap() != 12 || cap(l.data) != 3 {
+		t.Fatalf("unexpected slice: %#v %d", l, cap(l.data))
+	}
+
+	l.Append(9, 10, 11, 12)
+	if l.Len() != 13 || l.Cap() != 16 || l.Get(12) != 12 {
+		t.Fatalf("unexpected slice: %#v", l)
+	}
+
+	sub := l.Slice(0, 3)
+	sub.Compact()
+	if sub.String() != "[0, 1, 2]" || l.Segments() != 4 || l.Get(12) != 12 {
+		t.Fatalf("a sub-slice compacted its parent: %v %#v", sub, l)
+	}
+}
+
+func TestTrimFront(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 14; i++ {
+		l.Append(i)
+	}
+
+	q := l.Slice(9, 11)
+	q.TrimFront()
+	if q.Segments() != 1 || q.baseIdx != 1 || q.String() != "[9, 10]" {
+		t.Fatalf("unexpected slice: %#v", q)
+	}
+
+	q.Set(0, -9)
+	if l.Get(9) != 9 || q.Get(0) != -9 {
+		t.Fatalf("the parent was modified: %v %v", l, q)
+	}
+
+	q = l.Slice(8, 10)
+	q.TrimFront()
+	q.Append(-10)
+	if l.Get(10) != 10 || q.String() != "[8, 9, -10]" {
+		t.Fatalf("the parent was modified: %v %v", l, q)
+	}
+
+	q = l.Slice(12, 12)
+	q.TrimFront()
+	if q.Segments() != 0 || q.Len() != 0 {
+		t.Fatalf("unexpected slice: %#v", q)
+	}
+	q.Append(1)
+	if q.Get(0) != 1 || l.Get(12) != 12 {
+		t.Fatalf("unexpected slices: %v %v", l, q)
+	}
+}
+
+func TestToSlice(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 11; i++ {
+		l.Append(i)
+	}
+
+	if got := l.Slice(2, 9).ToSlice(); fmt.Sprint(got) != "[2 3 4 5 6 7 8]" || cap(got) != 7 {
+		t.Fatalf("unexpected slice: %v", got)
+	}
+	if got := l.Slice(8, 11).AppendToSlice([]interface{}{"x"}); fmt.Sprint(got) != "[x 8 9 10]" {
+		t.Fatalf("unexpected slice: %v", got)
+	}
+	if got := New(4).ToSlice(); got == nil || len(got) != 0 {
+		t.Fatalf("expected an empty slice, got %#v", got)
+	}
+}
+
+func TestSortFunc(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 100, 1000, 10000} {
+		l := NewSortable(16, func(a, b interface{}) bool { return a.(int) < b.(int) })
+		for i := 0; i < n; i++ {
+			l.Append(rand.Intn(n/2 + 1))
+		}
+
+		l.Sort()
+
+		for i := 1; i < l.Len(); i++ {
+			if a, b := l.Get(i-1).(int), l.Get(i).(int); a > b {
+				t.Fatalf("%d: unsorted at %d: %d > %d", n, i, a, b)
+			}
+		}
+	}
+}
+
+func TestSortRange(t *testing.T) {
+	l := NewSortable(4, func(a, b interface{}) bool { return a.(int) < b.(int) })
+	for i := 0; i < 20; i++ {
+		l.Append(19 - i)
+	}
+
+	l.SortRange(5, 15)
+
+	for i := 0; i < l.Len(); i++ {
+		exp := 19 - i
+		if i >= 5 && i < 15 {
+			exp = i
+		}
+		if l.Get(i).(int) != exp {
+			t.Fatalf("expected %d at %d, got %v", exp, i, l.Get(i))
+		}
+	}
+}
+
+func TestHeapSort(t *testing.T) {
+	less := func(a, b interface{}) bool { return a.(int) < b.(int) }
+	for _, n := range []int{1, 2, 3, 13, 50} {
+		// 3 elements on each side that must be left alone, across segment boundaries
+		l := NewSortable(4, less)
+		for i := 0; i < n+6; i++ {
+			l.Append(rand.Intn(n))
+		}
+		l.Set(0, -1)
+		l.Set(2, n+1)
+
+		l.sorter(less).heapSort(3, n+3)
+
+		if l.Get(0) != -1 || l.Get(2) != n+1 {
+			t.Fatalf("%d: heapSort modified elements outside of the range: %v", n, l)
+		}
+		for i := 4; i < n+3; i++ {
+			if a, b := l.Get(i-1).(int), l.Get(i).(int); a > b {
+				t.Fatalf("%d: unsorted at %d: %d > %d", n, i, a, b)
+			}
+		}
+	}
+
+	// a depth of 0 makes quickSort fall back to heapSort right away
+	l := NewSortable(8, less)
+	for i := 0; i < 100; i++ {
+		l.Append(rand.Intn(50))
+	}
+	l.sorter(less).quickSort(0, l.Len(), 0)
+	for i := 1; i < l.Len(); i++ {
+		if a, b := l.Get(i-1).(int), l.Get(i).(int); a > b {
+			t.Fatalf("unsorted at %d: %d > %d", i, a, b)
+		}
+	}
+}
+
+func TestInsertSortedBatch(t *testing.T) {
+	type kv struct{ k, v int }
+	l := NewSortable(4, func(a, b interface{}) bool { return a.(kv).k < b.(kv).k })
+	for i := 0; i < 10; i++ {
+		l.Append(kv{i * 2, 0})
+	}
+
+	l.InsertSortedBatch(kv{19, 1}, kv{-1, 1}, kv{4, 1}, kv{7, 1}, kv{100, 1}, kv{4, 2})
+
+	exp := []kv{{-1, 1}, {0, 0}, {2, 0}, {4, 0}, {4, 1}, {4, 2}, {6, 0}, {7, 1}, {8, 0}, {10, 0}, {12, 0}, {14, 0}, {16, 0}, {18, 0}, {19, 1}, {100, 1}}
+	if l.Len() != len(exp) {
+		t.Fatalf("expected %v, got %v", exp, l)
+	}
+	for i, e := range exp {
+		if l.Get(i).(kv) != e {
+			t.Fatalf("expected %v, got %v", exp, l)
+		}
+	}
+}
+
+func TestMergeSorted(t *testing.T) {
+	type kv struct{ k, src int }
+	less := func(a, b interface{}) bool { return a.(kv).k < b.(kv).k }
+	a, b, c, empty := NewSortable(4, less), New(4), New(8), New(4)
+	for i := 0; i < 10; i++ {
+		a.Append(kv{i * 3, 0})
+		b.Append(kv{i * 2, 1})
+		c.Append(kv{i, 2})
+	}
+
+	m := a.MergeSorted(b, empty, c)
+	if m.Len() != 30 {
+		t.Fatalf("expected length 30, got %d", m.Len())
+	}
+	for i := 1; i < m.Len(); i++ {
+		prev, cur := m.Get(i-1).(kv), m.Get(i).(kv)
+		if prev.k > cur.k || prev.k == cur.k && prev.src > cur.src {
+			t.Fatalf("unexpected order at %d: %v", i, m)
+		}
+	}
+}
+
+func TestSortSubSlice(t *testing.T) {
+	less := func(a, b interface{}) bool { return a.(int) < b.(int) }
+	for name, sortFn := range map[string]func(*Slice){
+		"sort.Sort": func(l *Slice) { sort.Sort(l) },
+		"Sort":      func(l *Slice) { l.Sort() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			l := NewSortable(4, less)
+			for i := 0; i < 20; i++ {
+				l.Append(19 - i)
+			}
+
+			// a view of a view to make sure offsets add up
+			sortFn(l.Slice(2, 18).Slice(3, 13))
+
+			for i := 0; i < l.Len(); i++ {
+				exp := 19 - i
+				if i >= 5 && i < 15 {
+					exp = i
+				}
+				if l.Get(i).(int) != exp {
+					t.Fatalf("expected %d at %d, got %v", exp, i, l.Get(i))
+				}
+			}
+		})
+	}
+}
+
+func TestReverse(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 7, 8, 9, 33} {
+		l := New(4)
+		for i := 0; i < n+2; i++ {
+			l.Append(i)
+		}
+
+		l.Slice(1, n+1).Reverse()
+
+		for i := 1; i <= n; i++ {
+			if exp := n + 1 - i; l.Get(i).(int) != exp {
+				t.Fatalf("%d: expected %d at %d, got %v", n, exp, i, l.Get(i))
+			}
+		}
+		if l.Get(0).(int) != 0 || l.Get(n+1).(int) != n+1 {
+			t.Fatalf("%d: reversed outside the sub-slice: %v", n, l)
+		}
+	}
+}
+
+func TestDiffIndices(t *testing.T) {
+	a, b := New(4), New(8)
+	for i := 0; i < 20; i++ {
+		a.Append(i)
+		if i%7 == 0 {
+			b.Append(-i)
+		} else {
+			b.Append(i)
+		}
+	}
+	b.Append(20, 21)
+
+	// compare against an unaligned view to make sure segments are paired correctly
+	eq := func(a, b interface{}) bool { return a.(int) == b.(int) }
+	idxs := a.Slice(1, 20).DiffIndices(b.Slice(1, 22), eq)
+	exp := []int{6, 13, 19, 20}
+	if len(idxs) != len(exp) {
+		t.Fatalf("expected %v, got %v", exp, idxs)
+	}
+	for i := range exp {
+		if idxs[i] != exp[i] {
+			t.Fatalf("expected %v, got %v", exp, idxs)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a, b := New(4), New(8)
+	for i := 0; i < 20; i++ {
+		a.Append(i)
+		b.Append(i)
+	}
+
+	eq := func(a, b interface{}) bool { return a.(int) == b.(int) }
+	if !a.Equal(b, eq) || !a.Slice(3, 17).Equal(b.Slice(3, 17), eq) {
+		t.Fatal("expected the slices to be equal")
+	}
+	if a.Slice(1, 20).Equal(b.Slice(1, 19), eq) {
+		t.Fatal("slices with different lengths can't be equal")
+	}
+
+	b.Set(13, -13)
+	if a.Equal(b, eq) {
+		t.Fatal("expected the slices to differ")
+	}
+}
+
+func TestHash(t *testing.T) {
+	h := func(w hash.Hash64, v interface{}) { fmt.Fprintf(w, "%q,", v) }
+	a, b := sliceOf("a", "b", "c", "d", "e"), sliceOf("x", "a", "b", "c", "d", "e")
+
+	if a.Hash(h) != b.Slice(1, 6).Hash(h) {
+		t.Fatal("expected equal hashes")
+	}
+	if a.Hash(h) == b.Hash(h) || sliceOf("ab", "c").Hash(h) == sliceOf("a", "bc").Hash(h) {
+		t.Fatal("expected different hashes")
+	}
+
+	b.Set(3, "z")
+	if a.Hash(h) == b.Slice(1, 6).Hash(h) {
+		t.Fatal("expected different hashes after Set")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	eq := func(a, b interface{}) bool { return a == b }
+	split := func(s string) *Slice {
+		l := New(4)
+		for _, c := range s {
+			l.Append(string(c))
+		}
+		return l
+	}
+
+	for _, tc := range []struct {
+		a, b string
+		d    int
+	}{
+		{"", "", 0},
+		{"abc", "abc", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"abcabba", "cbabac", 5},
+		{"xxabcabbayy", "xxcbabacyy", 5},
+		{"the quick brown fox", "the quack brown fix!", 5},
+	} {
+		a, b := split(tc.a), split(tc.b)
+		edits := a.Diff(b, eq)
+
+		// apply the edits to a and make sure we get b
+		var (
+			out  string
+			d, i int
+		)
+		for _, e := range edits {
+			switch e.Op {
+			case EditEqual:
+				if e.Index != i || !a.Slice(e.Index, e.Index+e.Len).Equal(b.Slice(e.OtherIndex, e.OtherIndex+e.Len), eq) {
+					t.Fatalf("%q %q: bad equal edit %+v: %v", tc.a, tc.b, e, edits)
+				}
+				out += tc.a[e.Index : e.Index+e.Len]
+				i += e.Len
+			case EditDelete:
+				if e.Index != i {
+					t.Fatalf("%q %q: bad delete edit %+v: %v", tc.a, tc.b, e, edits)
+				}
+				i += e.Len
+				d += e.Len
+			case EditInsert:
+				out += tc.b[e.OtherIndex : e.OtherIndex+e.Len]
+				d += e.Len
+			}
+			if len(out) > len(tc.b) || out != tc.b[:len(out)] {
+				t.Fatalf("%q %q: edits don't produce b: %q %v", tc.a, tc.b, out, edits)
+			}
+		}
+
+		if out != tc.b || i != len(tc.a) {
+			t.Fatalf("%q %q: edits don't produce b: %q %v", tc.a, tc.b, out, edits)
+		}
+		if d != tc.d {
+			t.Fatalf("%q %q: expected %d changes, got %d: %v", tc.a, tc.b, tc.d, d, edits)
+		}
+	}
+}
+
+type expiringInt struct {
+	v  int
+	dl time.Time
+}
+
+func (e expiringInt) Deadline() time.Time { return e.dl }
+
+func TestExpireBefore(t *testing.T) {
+	now := time.Now()
+	l := New(4)
+	for i := 0; i < 20; i++ {
+		switch i % 3 {
+		case 0:
+			l.Append(i)
+		case 1:
+			l.Append(expiringInt{i, now.Add(time.Duration(i-10) * time.Second)})
+		case 2:
+			l.Append(expiringInt{v: i})
+		}
+	}
+
+	if n := l.ExpireBefore(now); n != 3 {
+		t.Fatalf("expected 3 expired elements, got %d: %v", n, l)
+	}
+
+	if l.Len() != 17 {
+		t.Fatalf("expected length 17, got %d", l.Len())
+	}
+
+	prev := -1
+	l.ForEach(func(i int, v interface{}) (_ bool) {
+		n, ok := v.(int)
+		if e, isExp := v.(expiringInt); isExp {
+			n, ok = e.v, e.dl.IsZero() || e.v >= 10
+		}
+		if !ok || n <= prev {
+			t.Fatalf("unexpected element at %d: %v", i, v)
+		}
+		prev = n
+		return
+	})
+}
+
+func TestDeadlineTags(t *testing.T) {
+	now := time.Now()
+	l := NewSortable(4, func(a, b interface{}) bool { return a.(int) < b.(int) })
+	for i := 9; i >= 0; i-- {
+		if i%2 == 0 {
+			l.AppendWithDeadline(i, now.Add(time.Duration(i-5)*time.Second))
+		} else {
+			l.Append(i)
+		}
+	}
+
+	l.Sort()
+	if s := l.String(); s != "[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]" {
+		t.Fatalf("unexpected sorted slice: %s", s)
+	}
+	if dl, ok := l.Deadline(4); !ok || !dl.Equal(now.Add(-time.Second)) {
+		t.Fatalf("the deadline didn't move with the element: %v %v", dl, ok)
+	}
+	if _, ok := l.Deadline(3); ok {
+		t.Fatal("unexpected deadline for 3")
+	}
+
+	// the deadlines follow their elements when others are inserted or removed
+	if err := l.ApplyJSONPatch([]byte(`[{"op": "add", "path": "/0", "value": 100}, {"op": "remove", "path": "/3"}]`)); err != nil {
+		t.Fatal(err)
+	}
+	if dl, ok := l.Deadline(4); l.Get(4) != 4 || !ok || !dl.Equal(now.Add(-time.Second)) {
+		t.Fatalf("unexpected element or deadline at 4: %v %v %v", l.Get(4), dl, ok)
+	}
+
+	l.Set(1, 0)
+	if _, ok := l.Deadline(1); ok {
+		t.Fatal("Set should drop the deadline")
+	}
+	l.SetDeadline(2, now.Add(-time.Hour))
+	l.SetDeadline(8, time.Time{})
+
+	cp := l.Copy()
+	l.Compact()
+	if n := l.ExpireBefore(now); n != 2 || l.String() != "[100, 0, 3, 5, 6, 7, 8, 9]" {
+		t.Fatalf("unexpected result after expiring %d elements: %v", n, l)
+	}
+	if n := cp.ExpireBefore(now); n != 2 || cp.String() != "[100, 0, 3, 5, 6, 7, 8, 9]" {
+		t.Fatalf("Copy didn't keep the deadlines, expired %d elements: %v", n, cp)
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	l := NewSortable(4, func(a, b interface{}) bool { return a.(int) < b.(int) })
+	for i := 0; i < 100; i++ {
+		l.Append(i)
+	}
+
+	l.Shuffle(rand.New(rand.NewSource(42)))
+
+	moved := 0
+	for i := 0; i < l.Len(); i++ {
+		if l.Get(i).(int) != i {
+			moved++
+		}
+	}
+	if moved < 50 {
+		t.Fatalf("expected most elements to move, only %d did: %v", moved, l)
+	}
+
+	l.Sort()
+	for i := 0; i < l.Len(); i++ {
+		if l.Get(i).(int) != i {
+			t.Fatalf("elements lost during the shuffle: %v", l)
+		}
+	}
+}
+
+func TestSample(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	l := New(4)
+	for i := 0; i < 100; i++ {
+		l.Append(i)
+	}
+
+	for _, n := range []int{0, 1, 10, 100, 200} {
+		s := l.Sample(r, n)
+		exp := n
+		if exp > l.Len() {
+			exp = l.Len()
+		}
+		if s.Len() != exp {
+			t.Fatalf("expected %d elements, got %d", exp, s.Len())
+		}
+		for i := 1; i < s.Len(); i++ {
+			if s.Get(i-1).(int) >= s.Get(i).(int) {
+				t.Fatalf("expected sorted unique elements, got %v", s)
+			}
+		}
+	}
+
+	var (
+		rs   = New(4)
+		seen int
+	)
+	for i := 0; i < 1000; i++ {
+		seen = rs.ReservoirAppend(r, 10, seen, i)
+	}
+	if seen != 1000 || rs.Len() != 10 {
+		t.Fatalf("expected 1000 seen and 10 kept, got %d and %d", seen, rs.Len())
+	}
+	if rs.Get(9).(int) == 9 {
+		t.Fatalf("reservoir never replaced elements: %v", rs)
+	}
+}
+
+func TestSearchIndexed(t *testing.T) {
+	l := NewSortable(4, func(a, b interface{}) bool { return a.(int) < b.(int) })
+	vals := []int{5, 3, 9, 3, 7, 1, 8}
+	for _, v := range vals {
+		l.Append(v)
+	}
+
+	l.BuildSearchIndex()
+
+	for _, tc := range [][2]int{{5, 0}, {3, 1}, {9, 2}, {1, 5}, {8, 6}, {4, -1}, {0, -1}, {10, -1}} {
+		if idx := l.SearchIndexed(tc[0]); idx != tc[1] {
+			t.Errorf("expected %d for %d, got %d", tc[1], tc[0], idx)
+		}
+	}
+
+	for i, v := range vals {
+		if l.Get(i).(int) != v {
+			t.Fatalf("the data was reordered: %v", l)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	l := New(4)
+	for i := 1; i <= 10; i++ {
+		l.Append(i)
+	}
+
+	sums := l.Scan(0, func(acc, v interface{}) interface{} { return acc.(int) + v.(int) })
+	if sums.Len() != l.Len() {
+		t.Fatalf("expected length %d, got %d", l.Len(), sums.Len())
+	}
+
+	for i, exp := 0, 0; i < sums.Len(); i++ {
+		if exp += i + 1; sums.Get(i).(int) != exp {
+			t.Fatalf("expected %d at %d, got %v", exp, i, sums.Get(i))
+		}
+	}
+}
+
+func TestReduce(t *testing.T) {
+	l := New(4)
+	for i := 1; i <= 10; i++ {
+		l.Append(i)
+	}
+
+	sum := func(acc, v interface{}) interface{} { return acc.(int) + v.(int) }
+	if v := l.Reduce(0, sum).(int); v != 55 {
+		t.Fatalf("expected 55, got %d", v)
+	}
+	if v := l.Slice(5, 5).Reduce(42, sum).(int); v != 42 {
+		t.Fatalf("expected 42, got %d", v)
+	}
+}
+
+func TestAnyAllNone(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 10; i++ {
+		l.Append(i)
+	}
+
+	var calls int
+	is := func(n int) func(v interface{}) bool {
+		return func(v interface{}) bool { calls++; return v.(int) == n }
+	}
+	lt := func(n int) func(v interface{}) bool {
+		return func(v interface{}) bool { calls++; return v.(int) < n }
+	}
+
+	for i, tc := range []struct {
+		fn    func() bool
+		calls int
+	}{
+		{func() bool { return l.Any(is(3)) }, 4},
+		{func() bool { return !l.Any(is(10)) }, 10},
+		{func() bool { return l.All(lt(10)) }, 10},
+		{func() bool { return !l.All(lt(2)) }, 3},
+		{func() bool { return l.None(is(10)) }, 10},
+		{func() bool { return !l.None(is(0)) }, 1},
+		{func() bool { return l.Slice(0, 0).All(is(-1)) }, 0},
+	} {
+		calls = 0
+		if !tc.fn() {
+			t.Errorf("%d: unexpected result", i)
+		}
+		if calls != tc.calls {
+			t.Errorf("%d: expected %d calls, got %d", i, tc.calls, calls)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 10; i++ {
+		l.Append(i * 10)
+	}
+	gt := func(n int) func(v interface{}) bool {
+		return func(v interface{}) bool { return v.(int) > n }
+	}
+
+	if v, ok := l.Find(gt(45)); !ok || v.(int) != 50 {
+		t.Fatalf("expected 50, got %v (%v)", v, ok)
+	}
+	if v, ok := l.Find(gt(90)); ok || v != nil {
+		t.Fatalf("expected nothing, got %v (%v)", v, ok)
+	}
+	if idx := l.Slice(2, 10).FindIndex(gt(45)); idx != 3 {
+		t.Fatalf("expected 3, got %d", idx)
+	}
+	if idx := l.FindIndex(gt(90)); idx != -1 {
+		t.Fatalf("expected -1, got %d", idx)
+	}
+}
+
+func TestCount(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 20; i++ {
+		l.Append(i % 3)
+	}
+
+	if n := l.CountFunc(func(v interface{}) bool { return v.(int) > 0 }); n != 13 {
+		t.Fatalf("expected 13, got %d", n)
+	}
+	if n := l.Count(2, func(a, b interface{}) bool { return a.(int) == b.(int) }); n != 6 {
+		t.Fatalf("expected 6, got %d", n)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	l := sliceOf(2, 4, 4, 4, 5, 5, 7, 9)
+	count, min, max, mean, stddev := l.Summary(func(v interface{}) float64 { return float64(v.(int)) })
+	if count != 8 || min != 2 || max != 9 || mean != 5 || stddev != 2 {
+		t.Fatalf("unexpected summary: %v %v %v %v %v", count, min, max, mean, stddev)
+	}
+
+	if count, _, _, _, _ = l.Slice(0, 0).Summary(nil); count != 0 {
+		t.Fatalf("expected an empty summary, got %d", count)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 10; i++ {
+		l.Append(i)
+	}
+
+	l.Slice(2, 7).Transform(func(v interface{}) interface{} { return v.(int) * 10 })
+
+	for i := 0; i < l.Len(); i++ {
+		exp := i
+		if i >= 2 && i < 7 {
+			exp *= 10
+		}
+		if l.Get(i).(int) != exp {
+			t.Fatalf("expected %d at %d, got %v", exp, i, l.Get(i))
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	l := NewSortable(4, func(a, b interface{}) bool { return a.(int) < b.(int) })
+	for i := 0; i < 20; i++ {
+		l.Append(i)
+	}
+	even := func(v interface{}) bool { return v.(int)%2 == 0 }
+
+	f := l.Filter(even)
+	if f.Len() != 10 || f.segLen != l.segLen || f.lessFn == nil {
+		t.Fatalf("unexpected filtered slice: %#v", f)
+	}
+
+	if n := l.FilterInPlace(even); n != 10 || l.Len() != 10 {
+		t.Fatalf("expected 10 removed elements, got %d: %v", n, l)
+	}
+
+	for i := 0; i < 10; i++ {
+		if l.Get(i).(int) != i*2 || f.Get(i).(int) != i*2 {
+			t.Fatalf("unexpected element at %d: %v %v", i, l.Get(i), f.Get(i))
+		}
+	}
+}
+
+func TestDedupUnique(t *testing.T) {
+	vals := []interface{}{1, 1, 2, 3, 3, 3, 1, 4, 4, 2, 5, 5}
+
+	l := sliceOf(vals...)
+	if n := l.Dedup(func(a, b interface{}) bool { return a.(int) == b.(int) }); n != 5 {
+		t.Fatalf("expected 5 removed elements, got %d: %v", n, l)
+	}
+	if exp := "[1, 2, 3, 1, 4, 2, 5]"; l.String() != exp {
+		t.Fatalf("expected %s, got %v", exp, l)
+	}
+
+	l = sliceOf(vals...)
+	if n := l.Unique(func(v interface{}) string { return fmt.Sprint(v) }); n != 7 {
+		t.Fatalf("expected 7 removed elements, got %d: %v", n, l)
+	}
+	if exp := "[1, 2, 3, 4, 5]"; l.String() != exp {
+		t.Fatalf("expected %s, got %v", exp, l)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 20; i++ {
+		l.Append(i)
+	}
+
+	groups := l.GroupBy(func(v interface{}) interface{} { return v.(int) % 3 })
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(groups))
+	}
+
+	for k, g := range groups {
+		if g.segLen != l.segLen {
+			t.Fatalf("expected segLen %d, got %d", l.segLen, g.segLen)
+		}
+		for i := 0; i < g.Len(); i++ {
+			if exp := k.(int) + i*3; g.Get(i).(int) != exp {
+				t.Fatalf("group %v: expected %d at %d, got %v", k, exp, i, g.Get(i))
+			}
+		}
+	}
+}
+
+func TestMap(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 20; i++ {
+		l.Append(i)
+	}
+	double := func(v interface{}) interface{} { return v.(int) * 2 }
+
+	m := l.Slice(3, 17).Map(double)
+	l.MapInPlace(double)
+
+	if m.Len() != 14 {
+		t.Fatalf("expected length 14, got %d", m.Len())
+	}
+	for i := 0; i < l.Len(); i++ {
+		if l.Get(i).(int) != i*2 {
+			t.Fatalf("expected %d at %d, got %v", i*2, i, l.Get(i))
+		}
+		if i < m.Len() && m.Get(i).(int) != (i+3)*2 {
+			t.Fatalf("expected %d at %d, got %v", (i+3)*2, i, m.Get(i))
+		}
+	}
+}
+
+func TestMapParallel(t *testing.T) {
+	l := New(8)
+	for i := 0; i < 1000; i++ {
+		l.Append(i)
+	}
+
+	for _, workers := range []int{0, 1, 3, 1000} {
+		m := l.Slice(3, 997).MapParallel(workers, func(v interface{}) interface{} { return v.(int) * 2 })
+		if m.Len() != 994 {
+			t.Fatalf("expected length 994, got %d", m.Len())
+		}
+		for i := 0; i < m.Len(); i++ {
+			if exp := (i + 3) * 2; m.Get(i).(int) != exp {
+				t.Fatalf("%d workers: expected %d at %d, got %v", workers, exp, i, m.Get(i))
+			}
+		}
+	}
+}
+
+func TestStealFrom(t *testing.T) {
+	fill := func(segLen, from, to int) *Slice {
+		l := New(segLen)
+		for i := from; i < to; i++ {
+			l.Append(i)
+		}
+		return l
+	}
+
+	for _, tc := range []struct {
+		name   string
+		dst    *Slice
+		moved  int
+		srcCap int
+	}{
+		{"Segments", fill(4, 0, 8), 6, 12},
+		{"Unaligned", fill(4, 0, 6), 6, 20},
+		{"SegLen", fill(8, 0, 8), 6, 20},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			start := tc.dst.Len()
+			src := fill(4, start, start+14)
+			src.Grow(4) // spare segment
+
+			if n := tc.dst.StealFrom(src, 2); n != tc.moved {
+				t.Fatalf("expected %d moved elements, got %d", tc.moved, n)
+			}
+
+			if src.Len() != 8 || tc.dst.Len() != start+6 || src.Cap() != tc.srcCap {
+				t.Fatalf("unexpected lengths: %#v %#v", src, tc.dst)
+			}
+
+			for i := 0; i < tc.dst.Len(); i++ {
+				exp := i
+				if i >= start {
+					exp += 8
+				}
+				if tc.dst.Get(i).(int) != exp {
+					t.Fatalf("expected %d at %d, got %v", exp, i, tc.dst.Get(i))
+				}
+			}
+
+			src.Append(100, 101, 102)
+			if src.Get(10).(int) != 102 || tc.dst.Get(start).(int) != start+8 {
+				t.Fatalf("slices still share segments: %v %v", src, tc.dst)
+			}
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 10; i++ {
+		l.Append(i)
+	}
+
+	snap := l.Snapshot()
+	seg1 := &l.data[1][0]
+
+	l.Set(0, 100)
+	l.Pop()
+	l.Append(-1, -2, -3)
+	l.Slice(2, 4).Reverse()
+	if &l.data[1][0] != seg1 {
+		t.Fatal("a segment that wasn't written to was copied")
+	}
+	l.SortFunc(func(a, b interface{}) bool { return a.(int) < b.(int) })
+
+	if exp := "[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]"; snap.String() != exp {
+		t.Fatalf("the snapshot changed: %v", snap)
+	}
+	if exp := "[-3, -2, -1, 1, 2, 3, 4, 5, 6, 7, 8, 100]"; l.String() != exp {
+		t.Fatalf("expected %s, got %v", exp, l)
+	}
+
+	if !snap.Frozen() {
+		t.Fatal("expected the snapshot to be frozen")
+	}
+
+	shards := l.Split(2)
+	shards[0].Set(0, 99)
+	if snap.Get(0) != 0 || shards[0].Get(1) != -2 {
+		t.Fatalf("unexpected split: %v %v", shards, snap)
+	}
+}
+
+func TestSnapshotSetDuringIter(t *testing.T) {
+	for _, snap := range []bool{false, true} {
+		l := sliceOf(0, 1, 2, 3, 4, 5)
+		if snap {
+			sink = l.Snapshot()
+		}
+
+		var got []interface{}
+		l.ForEach(func(i int, v interface{}) (_ bool) {
+			if i == 0 {
+				l.Set(1, 100)
+				l.Set(5, 500)
+			}
+			got = append(got, v)
+			return
+		})
+		l.ForEachReverse(func(i int, v interface{}) (_ bool) {
+			if i == 5 {
+				l.Set(4, 400)
+			}
+			got = append(got, v)
+			return
+		})
+		if exp := "[0 100 2 3 4 500 500 400 3 2 100 0]"; fmt.Sprint(got) != exp {
+			t.Fatalf("snapshot %v: expected %s, got %v", snap, exp, got)
+		}
+
+		if snap {
+			sink = l.Snapshot()
+		}
+		it := l.Iter()
+		it.Next()
+		l.Set(1, -1)
+		if v := it.Next(); v != -1 {
+			t.Fatalf("snapshot %v: expected -1 from the iterator, got %v", snap, v)
+		}
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	l := sliceOf(5, 4, 3, 2, 1)
+	l.Freeze()
+
+	for name, fn := range map[string]func(){
+		"Append":       func() { l.Append(1) },
+		"Set":          func() { l.Set(0, 1) },
+		"SetUnchecked": func() { l.SetUnchecked(0, 1) },
+		"Pop":          func() { l.Pop() },
+		"Grow":         func() { l.Grow(10) },
+		"Sort":         func() { l.SortFunc(func(a, b interface{}) bool { return a.(int) < b.(int) }) },
+		"Filter":       func() { l.FilterInPlace(func(interface{}) bool { return false }) },
+		"Reverse":      func() { l.Reverse() },
+		"Unmarshal":    func() { json.Unmarshal([]byte(`[1]`), l) },
+		"SubSlice":     func() { l.Slice(1, 3).Set(0, 1) },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != "slice is frozen" {
+					t.Errorf("%s: expected a panic, got %v", name, r)
+				}
+			}()
+			fn()
+		}()
+	}
+
+	if exp := "[5, 4, 3, 2, 1]"; l.String() != exp || l.Get(0) != 5 {
+		t.Fatalf("the frozen slice was modified: %v", l)
+	}
+	cp := l.Copy()
+	cp.Append(0)
+	if cp.Frozen() || cp.Len() != 6 {
+		t.Fatalf("expected a mutable copy: %v", cp)
+	}
+}
+
+func TestRechunk(t *testing.T) {
+	for _, segLen := range []int{1, 2, 4, 8, 16, 64} {
+		l := New(8)
+		for i := 0; i < 37; i++ {
+			l.Append(i)
+		}
+		seg0 := &l.data[0][0]
+
+		r := l.Rechunk(segLen)
+		if l.Len() != 0 || l.Segments() != 0 || r.Len() != 37 || r.segLen != segLen-1 {
+			t.Fatalf("%d: unexpected slices: %#v %#v", segLen, l, r)
+		}
+		if segLen <= 8 && &r.data[0][0] != seg0 {
+			t.Fatalf("%d: the elements were copied", segLen)
+		}
+		for i := 0; i < r.Len(); i++ {
+			if r.Get(i) != i {
+				t.Fatalf("%d: expected %d, got %v", segLen, i, r.Get(i))
+			}
+		}
+
+		r.Append(37, 38)
+		if r.Get(38) != 38 || r.Len() != 39 || r.Cap() < 39 {
+			t.Fatalf("%d: unexpected slice after append: %#v", segLen, r)
+		}
+	}
+
+	r := sliceOf(1, 2, 3).Rechunk(1)
+	r.Append(4)
+	if r.String() != "[1, 2, 3, 4]" || r.Segments() != 4 {
+		t.Fatalf("unexpected slice: %#v", r)
+	}
+}
+
+func TestStreamChunks(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 23; i++ {
+		l.Append(i)
+	}
+
+	var msgs [][]interface{}
+	err := l.Slice(1, 23).StreamChunks(func(chunk []interface{}) error {
+		msgs = append(msgs, append([]interface{}(nil), chunk...))
+		return nil
+	}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 5 || len(msgs[4]) != 2 {
+		t.Fatalf("unexpected chunks: %v", msgs)
+	}
+
+	dst := New(8)
+	if err = dst.AppendChunks(func() (chunk []interface{}, err error) {
+		if len(msgs) == 0 {
+			return nil, io.EOF
+		}
+		chunk, msgs = msgs[0], msgs[1:]
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if dst.Len() != 22 {
+		t.Fatalf("expected length 22, got %d", dst.Len())
+	}
+	for i := 0; i < dst.Len(); i++ {
+		if dst.Get(i).(int) != i+1 {
+			t.Fatalf("expected %d at %d, got %v", i+1, i, dst.Get(i))
+		}
+	}
+
+	errStop := errors.New("stop")
+	calls := 0
+	if err = l.StreamChunks(func([]interface{}) error { calls++; return errStop }, 5); err != errStop || calls != 1 {
+		t.Fatalf("expected errStop after 1 call, got %v after %d", err, calls)
+	}
+}
+
+type flushWriter struct {
+	bytes.Buffer
+	flushes []int
+}
+
+func (w *flushWriter) Flush() { w.flushes = append(w.flushes, w.Len()) }
+
+func TestEncodeJSONBatched(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 100; i++ {
+		l.Append(i)
+	}
+
+	var w flushWriter
+	if err := l.EncodeJSONBatched(&w, 32); err != nil {
+		t.Fatal(err)
+	}
+
+	exp, _ := l.MarshalJSON()
+	if !bytes.Equal(w.Bytes(), exp) {
+		t.Fatalf("expected %s, got %s", exp, w.Bytes())
+	}
+	if len(w.flushes) < 5 || w.flushes[len(w.flushes)-1] != len(exp) {
+		t.Fatalf("unexpected flushes: %v", w.flushes)
+	}
+	for i := 1; i < len(w.flushes)-1; i++ {
+		if n := w.flushes[i] - w.flushes[i-1]; n < 32 || n > 40 {
+			t.Fatalf("unexpected batch size %d: %v", n, w.flushes)
+		}
+	}
+
+	l.Append(math.NaN())
+	w.Reset()
+	if err := l.EncodeJSONBatched(&w, 32); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 10} {
+		l := New(4)
+		for i := 0; i < 22; i++ {
+			l.Append(i)
+		}
+		seg0 := &l.data[0][0]
+
+		shards := l.Split(n)
+		if len(shards) != n || l.Len() != 0 || l.Segments() != 0 {
+			t.Fatalf("%d: unexpected split: %v %#v", n, shards, l)
+		}
+		if &shards[0].data[0][0] != seg0 {
+			t.Fatalf("%d: segments were copied", n)
+		}
+
+		next := 0
+		for _, sh := range shards {
+			for i := 0; i < sh.Len(); i++ {
+				if sh.Get(i).(int) != next {
+					t.Fatalf("%d: expected %d, got %v", n, next, sh.Get(i))
+				}
+				next++
+			}
+		}
+		if next != 22 {
+			t.Fatalf("%d: expected 22 elements, got %d", n, next)
+		}
+
+		// appending to a shard must not overwrite the next one
+		shards[0].Append(-1)
+		if n > 1 && shards[1].Len() > 0 && shards[1].Get(0).(int) == -1 {
+			t.Fatalf("%d: shards share segments", n)
+		}
+	}
+}
+
 func TestJSON(t *testing.T) {
 	testData := intJSONData(128)
 
@@ -55,9 +1913,45 @@ func TestJSON(t *testing.T) {
 	}
 	j, _ := json.Marshal(l)
 
-	t.Run("Marshal", func(t *testing.T) {
-		if !bytes.Equal(j, testData) {
-			t.Fatalf("expected:\n\t%s\ngot:\n\t:%s", testData, j)
+	t.Run("Marshal", func(t *testing.T) {
+		if !bytes.Equal(j, testData) {
+			t.Fatalf("expected:\n\t%s\ngot:\n\t:%s", testData, j)
+		}
+	})
+
+	t.Run("Primitives", func(t *testing.T) {
+		vals := []interface{}{
+			nil, true, false, 0, -42, int64(1 << 60), 1.5, -0.0, 1e-7, 1e21, 123456789.125, 3.0,
+			"", "plain", "quote\" back\\slash", "<a href='x'>&</a>", "\b\f\n\r\t\x01", "\u2028\u2029", "héllo", "bad\xffutf8",
+			uint8(7), []int{1, 2}, map[string]int{"a": 1},
+		}
+
+		exp, _ := json.Marshal(vals)
+		got, err := sliceOf(vals...).MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, exp) {
+			t.Fatalf("expected:\n\t%s\ngot:\n\t%s", exp, got)
+		}
+
+		if _, err = sliceOf(math.NaN()).MarshalJSON(); err == nil {
+			t.Fatal("expected an error for NaN")
+		}
+	})
+
+	t.Run("AppendJSON", func(t *testing.T) {
+		buf := []byte("data: ")
+		buf, err := l.Slice(0, 3).AppendJSON(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := "data: [0,1,2]"; string(buf) != exp {
+			t.Fatalf("expected %s, got %s", exp, buf)
+		}
+
+		if buf, err = sliceOf(1, math.Inf(1)).AppendJSON(buf[:0]); err == nil || len(buf) != 0 {
+			t.Fatalf("expected an error and an empty buffer, got %v %q", err, buf)
 		}
 	})
 
@@ -101,6 +1995,203 @@ func TestJSON(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("Reuse", func(t *testing.T) {
+		var ss Slice
+		for i := 0; i < 2; i++ {
+			if err := json.Unmarshal(j, &ss); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if ss.Len() != l.Len() {
+			t.Fatalf("expected length %d, got %d", l.Len(), ss.Len())
+		}
+
+		ss.SetUnmarshalOptions(UnmarshalOptions{Append: true})
+		if err := json.Unmarshal(j, &ss); err != nil {
+			t.Fatal(err)
+		}
+		if ss.Len() != 2*l.Len() {
+			t.Fatalf("expected length %d, got %d", 2*l.Len(), ss.Len())
+		}
+	})
+
+	t.Run("Limits", func(t *testing.T) {
+		for _, tc := range []struct {
+			opts UnmarshalOptions
+			in   string
+			ok   bool
+		}{
+			{UnmarshalOptions{MaxElements: 3}, `[1, 2, 3]`, true},
+			{UnmarshalOptions{MaxElements: 3}, `[1, 2, 3, 4]`, false},
+			{UnmarshalOptions{MaxBytes: 9}, `[1, 2, 3]`, true},
+			{UnmarshalOptions{MaxBytes: 8}, `[1, 2, 3]`, false},
+			{UnmarshalOptions{MaxDepth: 2}, `[[1, {"a": "]]"}], 2]`, true},
+			{UnmarshalOptions{MaxDepth: 2}, `[[1, {"a": [3]}], 2]`, false},
+			{UnmarshalOptions{MaxDepth: 2, SkipInvalid: true}, `[[1, {"a": [3]}], 2]`, false},
+			{UnmarshalOptions{SkipInvalid: true}, `[1, 2, x]`, false},
+		} {
+			var ss Slice
+			ss.Append("a", "b", "c")
+			ss.SetUnmarshalOptions(tc.opts)
+			err := json.Unmarshal([]byte(tc.in), &ss)
+			if (err == nil) != tc.ok {
+				t.Errorf("%+v %s: unexpected error: %v", tc.opts, tc.in, err)
+			}
+			if _, skipped := err.(DecodeErrors); err != nil && !skipped && ss.String() != "[a, b, c]" {
+				t.Errorf("%+v %s: a rejected input changed the slice: %v", tc.opts, tc.in, ss.String())
+			}
+		}
+	})
+
+	t.Run("SkipInvalid", func(t *testing.T) {
+		var ss Slice
+		ss.SetUnmarshalType(0)
+		ss.SetUnmarshalOptions(UnmarshalOptions{SkipInvalid: true})
+
+		err := json.Unmarshal([]byte(`[1, "2", 3, {"a": 4}, 5]`), &ss)
+		errs, ok := err.(DecodeErrors)
+		if !ok || len(errs) != 2 || errs[0].Index != 1 || errs[1].Index != 3 {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if exp := "[1, 3, 5]"; ss.String() != exp {
+			t.Fatalf("expected %s, got %v", exp, ss.String())
+		}
+
+		ss.SetUnmarshalOptions(UnmarshalOptions{})
+		if err = json.Unmarshal([]byte(`[1, "2", 3]`), &ss); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
+
+func TestCodec(t *testing.T) {
+	var encs, decs int
+	l := NewSortable(4, func(a, b interface{}) bool { return a.(int) < b.(int) })
+	l.SetCodec(
+		func(v interface{}) interface{} { encs++; return fmt.Sprint(v) },
+		func(v interface{}) interface{} { decs++; n, _ := strconv.Atoi(v.(string)); return n },
+	)
+
+	for i := 9; i >= 0; i-- {
+		l.Append(i)
+	}
+	if encs != 10 || l.GetUnchecked(0) != "9" {
+		t.Fatalf("values weren't encoded: %d %#v", encs, l.GetUnchecked(0))
+	}
+
+	l.Sort()
+	l.Transform(func(v interface{}) interface{} { return v.(int) * 2 })
+	l.Set(0, 1)
+	if exp := "[1, 2, 4, 6, 8, 10, 12, 14, 16, 18]"; l.String() != exp {
+		t.Fatalf("expected %s, got %s", exp, l)
+	}
+
+	if v := l.Pop(); v != 18 {
+		t.Fatalf("expected 18, got %#v", v)
+	}
+	if v := l.Copy().Get(1); v != 2 {
+		t.Fatalf("expected 2, got %#v", v)
+	}
+	if decs == 0 {
+		t.Fatal("dec was never called")
+	}
+}
+
+type point struct{ X, Y int }
+
+func TestElemMode(t *testing.T) {
+	j := []byte(`[{"X": 1, "Y": 2}, {"X": 3, "Y": 4}]`)
+
+	for _, tc := range []struct {
+		mode ElemMode
+		typ  interface{}
+		exp  string
+	}{
+		{ElemAsIs, point{}, "segmentedSlice.point"},
+		{ElemAsIs, &point{}, "*segmentedSlice.point"},
+		{ElemByValue, point{}, "segmentedSlice.point"},
+		{ElemByValue, &point{}, "segmentedSlice.point"},
+		{ElemByPointer, point{}, "*segmentedSlice.point"},
+		{ElemByPointer, &point{}, "*segmentedSlice.point"},
+	} {
+		ss := New(4, WithElemMode(tc.mode))
+		ss.SetUnmarshalType(tc.typ)
+		if err := json.Unmarshal(j, ss); err != nil {
+			t.Fatal(err)
+		}
+
+		ss.Append(point{5, 6}, &point{7, 8})
+		for i := 0; i < ss.Len(); i++ {
+			v := ss.Get(i)
+			if tc.mode == ElemAsIs && i > 1 {
+				continue
+			}
+			if got := fmt.Sprintf("%T", v); got != tc.exp {
+				t.Errorf("%v %T: expected %s at %d, got %s", tc.mode, tc.typ, tc.exp, i, got)
+			}
+		}
+	}
+
+	p := &point{1, 2}
+	l := New(4, WithElemMode(ElemByPointer))
+	l.Append(p, point{3, 4})
+	cp, dcp := l.Copy(), l.DeepCopy()
+	p.X = 100
+	if cp.Get(0).(*point).X != 100 || dcp.Get(0).(*point).X != 1 {
+		t.Fatalf("unexpected copies: %v %v", cp.Get(0), dcp.Get(0))
+	}
+	if dcp.Get(1).(*point) == l.Get(1).(*point) || dcp.ElemMode() != ElemByPointer {
+		t.Fatal("DeepCopy shares pointers")
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	l := New(4)
+	for i := 0; i < 10; i++ {
+		l.Append(i)
+	}
+	l.SetUnmarshalType(0)
+
+	patch := `[
+		{"op": "remove", "path": "/0"},
+		{"op": "add", "path": "/-", "value": 10},
+		{"op": "add", "path": "/4", "value": 100},
+		{"op": "replace", "path": "/10", "value": 11}
+	]`
+	if err := l.ApplyJSONPatch([]byte(patch)); err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := "[1, 2, 3, 4, 100, 5, 6, 7, 8, 9, 11]"; l.String() != exp {
+		t.Fatalf("expected %s, got %s", exp, l)
+	}
+
+	for _, patch := range []string{
+		`[{"op": "remove", "path": "/0"}, {"op": "remove", "path": "/10"}]`,
+		`[{"op": "add", "path": "/01", "value": 1}]`,
+		`[{"op": "replace", "path": "/-", "value": 1}]`,
+		`[{"op": "replace", "path": "/0", "value": "x"}]`,
+		`[{"op": "move", "from": "/0", "path": "/1"}]`,
+	} {
+		if err := l.ApplyJSONPatch([]byte(patch)); err == nil {
+			t.Fatalf("%s: expected an error", patch)
+		}
+		if l.Len() != 11 || l.Get(0).(int) != 1 {
+			t.Fatalf("%s: slice modified by a failed patch: %v", patch, l)
+		}
+	}
+
+	sub := l.Slice(2, 8)
+	if err := sub.ApplyJSONPatch([]byte(`[{"op": "remove", "path": "/0"}]`)); err != nil {
+		t.Fatal(err)
+	}
+	if exp := "[4, 100, 5, 6, 7]"; sub.String() != exp {
+		t.Fatalf("expected %s, got %s", exp, sub)
+	}
+	if exp := "[1, 2, 3, 4, 100, 5, 6, 7, 8, 9, 11]"; l.String() != exp {
+		t.Fatalf("the parent was modified: %s", l)
+	}
 }
 
 func BenchmarkAppendSegmentedSlice(b *testing.B) {
@@ -129,6 +2220,96 @@ func BenchmarkAppendNormalSlice(b *testing.B) {
 	}
 }
 
+func BenchmarkSortInterface(b *testing.B) {
+	benchSort(b, func(l *Slice) { sort.Sort(l) })
+}
+
+func BenchmarkSortFunc(b *testing.B) {
+	benchSort(b, func(l *Slice) { l.Sort() })
+}
+
+func benchSort(b *testing.B, sortFn func(l *Slice)) {
+	const sliceLen = 10000
+	r := rand.New(rand.NewSource(0))
+	vals := make([]interface{}, sliceLen)
+	for i := range vals {
+		vals[i] = r.Int()
+	}
+
+	l := NewSortable(128, func(a, b interface{}) bool { return a.(int) < b.(int) })
+	l.Append(vals...)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		for i, v := range vals {
+			l.Set(i, v)
+		}
+		b.StartTimer()
+		sortFn(l)
+	}
+}
+
+func BenchmarkIterAt(b *testing.B) {
+	l := New(128)
+	for i := 0; i < 16; i++ {
+		l.Append(i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for it := l.IterAt(0, l.Len()); it.More(); {
+			sink = it.Next()
+		}
+	}
+}
+
+func BenchmarkIterVal(b *testing.B) {
+	l := New(128)
+	for i := 0; i < 16; i++ {
+		l.Append(i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var it Iterator
+	for i := 0; i < b.N; i++ {
+		for it.ResetTo(l, 0, l.Len()); it.More(); {
+			sink = it.Next()
+		}
+	}
+}
+
+func BenchmarkGetLoop(b *testing.B) {
+	l := New(128)
+	for i := 0; i < 10000; i++ {
+		l.Append(i)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < l.Len(); j++ {
+			sink = l.Get(j)
+		}
+	}
+}
+
+func BenchmarkSeqReader(b *testing.B) {
+	l := New(128)
+	for i := 0; i < 10000; i++ {
+		l.Append(i)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		r := l.SeqReader()
+		for j := 0; j < l.Len(); j++ {
+			sink = r.Get(j)
+		}
+	}
+}
+
 func intJSONData(ln int) []byte {
 	s := make([]interface{}, ln)
 	for i := range s {
@@ -138,7 +2319,377 @@ func intJSONData(ln int) []byte {
 	return j
 }
 
+func sliceOf(vals ...interface{}) *Slice {
+	l := New(4)
+	l.Append(vals...)
+	return l
+}
+
 func printJSON(tb testing.TB, v interface{}) {
 	j, _ := json.MarshalIndent(v, "", "  ")
 	tb.Logf("%s", j)
 }
+
+func TestGeometricSegments(t *testing.T) {
+	l := New(16, WithGeometricSegments(2))
+	for i := 0; i < 100; i++ {
+		l.Append(i)
+	}
+
+	var lens []int
+	for _, seg := range l.data {
+		lens = append(lens, len(seg))
+	}
+	if fmt.Sprint(lens) != "[2 4 8 16 16 16 16 16 16]" || l.Cap() != 110 {
+		t.Fatalf("unexpected segments: %v, cap %d", lens, l.Cap())
+	}
+	for i := 0; i < l.Len(); i++ {
+		if l.Get(i) != i {
+			t.Fatalf("expected %d, got %v", i, l.Get(i))
+		}
+	}
+
+	sub := l.Slice(5, 95)
+	n := 0
+	for _, it := range sub.SplitIter(3) {
+		for it.More() {
+			if i, v := it.NextIndex(); v != i+5 {
+				t.Fatalf("SplitIter: expected %d, got %v", i+5, v)
+			}
+			n++
+		}
+	}
+	if n != 90 {
+		t.Fatalf("SplitIter covered %d elements", n)
+	}
+
+	l.SortFunc(func(a, b interface{}) bool { return a.(int) > b.(int) })
+	if l.Get(0) != 99 || l.Get(99) != 0 {
+		t.Fatalf("unexpected sort: %v", l)
+	}
+
+	l.Truncate(10, true)
+	if l.Segments() != 3 || l.Cap() != 14 {
+		t.Fatalf("unexpected truncate: %#v", l)
+	}
+
+	sub = l.Slice(7, 10)
+	sub.TrimFront()
+	if sub.Len() != 3 || sub.Get(0) != 92 || sub.Get(2) != 90 || len(sub.data[0]) != 2 {
+		t.Fatalf("unexpected TrimFront: %#v", sub)
+	}
+
+	shards := l.Split(3)
+	if shards[0].Len() != 4 || shards[2].Get(2) != 90 || len(shards[1].data[0]) != 2 || l.Len() != 0 {
+		t.Fatalf("unexpected shards: %v", shards)
+	}
+
+	r := shards[0].Rechunk(2)
+	if r.geo != nil || r.Len() != 4 || r.Get(3) != 96 {
+		t.Fatalf("unexpected Rechunk: %#v", r)
+	}
+}
+
+func TestGrowBatch(t *testing.T) {
+	l := New(4)
+	l.Grow(1000)
+	if l.Cap() < 1000 || cap(l.data[0]) != 4 || cap(l.data[249]) != 4 {
+		t.Fatalf("unexpected segments: %d, cap %d", l.Segments(), cap(l.data[0]))
+	}
+	for i := 0; i < 1000; i++ {
+		l.Append(i)
+	}
+	for i := 0; i < 1000; i++ {
+		if l.Get(i) != i {
+			t.Fatalf("expected %d, got %v", i, l.Get(i))
+		}
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		l := New(4)
+		l.Grow(1000)
+		sink = l
+	})
+	if allocs > 5 {
+		t.Fatalf("expected a single allocation for the segments, got %v allocs", allocs)
+	}
+}
+
+func TestLazySegments(t *testing.T) {
+	l := New(4, WithLazySegments())
+	l.Reserve(1000)
+	if l.Cap() < 1000 || l.allocated() != 0 {
+		t.Fatalf("unexpected reserve: cap %d, %d segments allocated", l.Cap(), l.allocated())
+	}
+
+	l.Append(0, 1, 2, 3, 4)
+	if l.allocated() != 2 || l.Get(4) != 4 {
+		t.Fatalf("unexpected append: %#v, %d segments allocated", l, l.allocated())
+	}
+
+	l.Resize(10, -1)
+	l.Set(9, 9)
+	l.insert(0, -2)
+	if l.allocated() != 3 || l.Get(10) != 9 || l.Get(9) != -1 {
+		t.Fatalf("unexpected slice: %v, %d segments allocated", l, l.allocated())
+	}
+
+	snap := l.Snapshot()
+	l.Append(11)
+	l.Set(0, -3)
+	if snap.Get(0) != -2 || snap.Len() != 11 || l.Get(11) != 11 || l.Get(0) != -3 {
+		t.Fatalf("unexpected snapshot: %v %v", snap, l)
+	}
+
+	r := l.Rechunk(8)
+	if r.Len() != 12 || r.Get(11) != 11 || r.allocated() != 2 {
+		t.Fatalf("unexpected Rechunk: %v", r)
+	}
+	r.Truncate(0, true)
+	if r.Cap() != 0 || r.Segments() != 0 {
+		t.Fatalf("unexpected truncate: %#v", r)
+	}
+}
+
+func TestGrowOverflow(t *testing.T) {
+	l := sliceOf(1, 2, 3)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	l.Grow(maxInt - 2)
+}
+
+func TestStats(t *testing.T) {
+	l := New(4)
+	l.Append(1, 2, 3, 4, 5, 6)
+
+	exp := SliceStats{Len: 6, Cap: 8, Segments: 2, Allocated: 2, LastFill: 0.5, Wasted: 2, Allocs: 1}
+	if st := l.Stats(); st != exp {
+		t.Fatalf("expected %+v, got %+v", exp, st)
+	}
+
+	l.Append(7, 8, 9)
+	exp = SliceStats{Len: 3, Cap: 12, Segments: 3, Allocated: 3, LastFill: 1, Wasted: 4, BaseIdx: 5, Allocs: 2}
+	if st := l.Slice(5, 8).Stats(); st != exp {
+		t.Fatalf("expected %+v, got %+v", exp, st)
+	}
+}
+
+var metricsSeq int // expvar names are global, so every run of TestMetrics needs a new one
+
+func TestMetrics(t *testing.T) {
+	metricsSeq++
+	name := fmt.Sprintf("segmentedSlice_test_%d", metricsSeq)
+	m := NewExpvarMetrics(name)
+	l := New(4, WithMetrics(m))
+	l.Append(1, 2, 3, 4, 5)
+	l.Append(6)
+
+	sub := l.Slice(1, 3)
+	sub.Append(7)
+	if sub.metrics != m {
+		t.Fatal("the copy lost the metrics")
+	}
+
+	if s := expvar.Get(name).String(); s != `{"appends": 7, "copies": 2, "grows": 3}` {
+		t.Fatalf("unexpected metrics: %s", s)
+	}
+}
+
+func TestGetSetE(t *testing.T) {
+	l := sliceOf(1, 2, 3)
+	if v, err := l.GetE(2); err != nil || v != 3 {
+		t.Fatalf("expected 3, got %v %v", v, err)
+	}
+	if err := l.SetE(0, 10); err != nil || l.Get(0) != 10 {
+		t.Fatalf("unexpected SetE: %v %v", l, err)
+	}
+
+	_, err := l.GetE(-1)
+	if exp := (ErrIndexOutOfRange{-1, 3}); err != exp {
+		t.Fatalf("expected %v, got %v", exp, err)
+	}
+	err = l.Slice(1, 3).SetE(2, 0)
+	var oor ErrIndexOutOfRange
+	if !errors.As(err, &oor) || oor.Index != 2 || oor.Len != 2 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewChecked(t *testing.T) {
+	if l, err := NewChecked(8); err != nil || l.segLen != 7 {
+		t.Fatalf("unexpected slice: %#v %v", l, err)
+	}
+	for _, segLen := range []int{0, -4, 12} {
+		if _, err := NewChecked(segLen); err != ErrInvalidSegLen {
+			t.Fatalf("%d: expected ErrInvalidSegLen, got %v", segLen, err)
+		}
+	}
+}
+
+func TestNewWithCapacity(t *testing.T) {
+	l := NewWithCapacity(4, 10)
+	if l.Len() != 0 || l.Cap() < 10 || l.Segments() != 3 {
+		t.Fatalf("unexpected slice: %#v", l)
+	}
+
+	l.Append(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	if l.Segments() != 3 || l.Get(9) != 10 {
+		t.Fatalf("the slice grew: %#v", l)
+	}
+}
+
+func TestNegativeIndex(t *testing.T) {
+	l := sliceOf(1, 2, 3, 4, 5)
+	if l.At(-1) != 5 || l.At(-5) != 1 || l.At(0) != 1 {
+		t.Fatalf("unexpected At: %v %v %v", l.At(-1), l.At(-5), l.At(0))
+	}
+
+	l.SetAt(-2, 40)
+	if l.Get(3) != 40 {
+		t.Fatalf("unexpected SetAt: %v", l)
+	}
+
+	if s := fmt.Sprint(l.SliceAt(-3, -1)); s != "[3, 40]" {
+		t.Fatalf("unexpected SliceAt: %s", s)
+	}
+	if s := fmt.Sprint(l.SliceAt(1, -1).SliceAt(-2, 3)); s != "[3, 40]" {
+		t.Fatalf("unexpected SliceAt: %s", s)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	l.At(-6)
+}
+
+func TestFirstLast(t *testing.T) {
+	l := sliceOf(1, 2, 3, 4, 5)
+	if v, ok := l.First(); !ok || v != 1 {
+		t.Fatalf("unexpected First: %v %v", v, ok)
+	}
+	if v, ok := l.Slice(1, 4).Last(); !ok || v != 4 {
+		t.Fatalf("unexpected Last: %v %v", v, ok)
+	}
+
+	l = New(4)
+	if v, ok := l.First(); ok || v != nil {
+		t.Fatalf("unexpected First: %v %v", v, ok)
+	}
+	if v, ok := l.Last(); ok || v != nil {
+		t.Fatalf("unexpected Last: %v %v", v, ok)
+	}
+}
+
+func TestTryPopN(t *testing.T) {
+	l := sliceOf(1, 2, 3, 4, 5, 6, 7)
+	if v, ok := l.TryPop(); !ok || v != 7 {
+		t.Fatalf("unexpected TryPop: %v %v", v, ok)
+	}
+	if out := l.PopN(5); fmt.Sprint(out) != "[2 3 4 5 6]" || l.Len() != 1 {
+		t.Fatalf("unexpected PopN: %v %v", out, l)
+	}
+	if out := l.PopN(3); fmt.Sprint(out) != "[1]" || l.Len() != 0 {
+		t.Fatalf("unexpected PopN: %v %v", out, l)
+	}
+	if out := l.PopN(3); out != nil {
+		t.Fatalf("unexpected PopN: %v", out)
+	}
+	if v, ok := l.TryPop(); ok || v != nil {
+		t.Fatalf("unexpected TryPop: %v %v", v, ok)
+	}
+}
+
+func TestTypedGetters(t *testing.T) {
+	var l Slice
+	if err := json.Unmarshal([]byte(`[1, 2.5, "x", true, 1e300]`), &l); err != nil {
+		t.Fatal(err)
+	}
+	l.Append(int8(-3), uint64(math.MaxUint64), json.Number("42"))
+
+	if n, ok := l.GetInt(0); !ok || n != 1 {
+		t.Fatalf("unexpected GetInt(0): %v %v", n, ok)
+	}
+	if n, ok := l.GetInt(1); ok {
+		t.Fatalf("unexpected GetInt(1): %v", n)
+	}
+	if _, ok := l.GetInt(4); ok {
+		t.Fatal("unexpected GetInt(4)")
+	}
+	if n, ok := l.GetInt(5); !ok || n != -3 {
+		t.Fatalf("unexpected GetInt(5): %v %v", n, ok)
+	}
+	if _, ok := l.GetInt64(6); ok {
+		t.Fatal("unexpected GetInt64(6)")
+	}
+	if n, ok := l.GetInt64(7); !ok || n != 42 {
+		t.Fatalf("unexpected GetInt64(7): %v %v", n, ok)
+	}
+	if f, ok := l.GetFloat64(1); !ok || f != 2.5 {
+		t.Fatalf("unexpected GetFloat64(1): %v %v", f, ok)
+	}
+	if f, ok := l.GetFloat64(5); !ok || f != -3 {
+		t.Fatalf("unexpected GetFloat64(5): %v %v", f, ok)
+	}
+	if _, ok := l.GetFloat64(2); ok {
+		t.Fatal("unexpected GetFloat64(2)")
+	}
+	if s, ok := l.GetString(2); !ok || s != "x" {
+		t.Fatalf("unexpected GetString(2): %v %v", s, ok)
+	}
+	if b, ok := l.GetBool(3); !ok || !b {
+		t.Fatalf("unexpected GetBool(3): %v %v", b, ok)
+	}
+	if _, ok := l.GetString(0); ok {
+		t.Fatal("unexpected GetString(0)")
+	}
+}
+
+func TestSegmentPool(t *testing.T) {
+	p := NewSegmentPool()
+	l := New(4, WithSegmentPool(p))
+	l.Append(1, 2, 3, 4, 5, 6, 7, 8, 9)
+	if l.Segments() != 3 {
+		t.Fatalf("unexpected slice: %#v", l)
+	}
+
+	l.Truncate(2, true)
+	if l.Segments() != 1 || l.Cap() != 4 {
+		t.Fatalf("unexpected truncate: %#v", l)
+	}
+
+	l.Reset()
+	if l.Len() != 0 || l.Segments() != 0 || l.Cap() != 0 {
+		t.Fatalf("unexpected reset: %#v", l)
+	}
+
+	// sync.Pool may drop the segments, but the ones it returns must be clean
+	for i := 0; i < 4; i++ {
+		seg := p.get(4)
+		if len(seg) != 4 || seg[0] != nil || seg[1] != nil {
+			t.Fatalf("unexpected segment from the pool: %v", seg)
+		}
+	}
+
+	l2 := New(4, WithSegmentPool(p))
+	l2.Append(10, 11, 12, 13, 14)
+	if s := fmt.Sprint(l2); s != "[10, 11, 12, 13, 14]" {
+		t.Fatalf("unexpected slice: %s", s)
+	}
+
+	// sub-slices must not release their parent's segments
+	l.Append(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	l.Slice(0, 3).Reset()
+	l.Slice(0, 6).Truncate(2, true)
+	l.Slice(0, 3).Compact()
+
+	l3 := New(4, WithSegmentPool(p))
+	l3.Append(-1, -2, -3, -4, -5, -6, -7, -8, -9, -10)
+	if s := fmt.Sprint(l); s != "[0, 1, 2, 3, 4, 5, 6, 7, 8, 9]" || l.Segments() != 3 || l.Get(9) != 9 {
+		t.Fatalf("the parent was modified: %s", s)
+	}
+}
@@ -0,0 +1,86 @@
+package segmentedSlice
+
+// Snapshot returns a frozen view of the slice that shares its segments with ss, but doesn't see any later modification of ss.
+// The segments are copied on write, the first write to a shared segment by either slice copies only that segment,
+// so taking a snapshot of a huge slice is cheap and readers can hold it as long as they need a consistent view.
+// Calling Snapshot on a sub-slice returns a Copy, since the parent slice can't track the shared segments.
+func (ss *Slice) Snapshot() *Slice {
+	if ss.view || ss.baseIdx != 0 {
+		return ss.Copy()
+	}
+
+	if ss.shared == nil {
+		ss.shared = make([]bool, len(ss.data))
+	}
+	for i := range ss.shared {
+		ss.shared[i] = true
+	}
+
+	cp := *ss
+	cp.data = append([][]interface{}(nil), ss.data...)
+	cp.shared = append([]bool(nil), ss.shared...)
+	cp.frozen = true
+	return &cp
+}
+
+// Freeze makes the slice read-only, any method that modifies it panics afterwards.
+// Freezing a sub-slice doesn't freeze its parent, and a frozen slice can't be unfrozen, use Copy to get a mutable slice.
+func (ss *Slice) Freeze() { ss.frozen = true }
+
+// Frozen returns true if the slice is read-only, see Freeze and Snapshot.
+func (ss *Slice) Frozen() bool { return ss.frozen }
+
+func (ss *Slice) checkFrozen() {
+	if ss.frozen {
+		panic("slice is frozen")
+	}
+}
+
+// own makes sure the segments holding [start, end) aren't shared with a snapshot by copying the shared ones
+// and allocates the lazy ones (see WithLazySegments), it must be called before writing to the segments directly.
+// It returns true if any segment was replaced. It panics if the slice is frozen.
+func (ss *Slice) own(start, end int) (copied bool) {
+	ss.checkFrozen()
+	if (ss.shared == nil && !ss.lazy) || start >= end {
+		return
+	}
+
+	first, _ := ss.index(ss.baseIdx + start)
+	last, _ := ss.index(ss.baseIdx + end - 1)
+	for di := first; di <= last; di++ {
+		if ss.data[di] == nil {
+			ss.data[di] = ss.newSegment(ss.segSize(di))
+			copied = true
+		} else if ss.isShared(di) {
+			seg := ss.newSegment(len(ss.data[di]))
+			copy(seg, ss.data[di])
+			ss.data[di] = seg
+			copied = true
+		}
+		if ss.shared != nil {
+			ss.shared[di] = false
+		}
+	}
+	return
+}
+
+// unshare copies all the shared segments that hold elements and replaces the spare ones with new segments,
+// it is used before handing segments over to other slices. ss must not be a sub-slice.
+func (ss *Slice) unshare() {
+	if ss.shared == nil {
+		return
+	}
+
+	ss.own(0, ss.len)
+	for di, shared := range ss.shared {
+		if shared {
+			ss.data[di] = ss.newSegment(ss.segSize(di))
+		}
+	}
+	ss.shared = nil
+}
+
+// isShared returns true if the segment di is shared with a snapshot.
+func (ss *Slice) isShared(di int) bool {
+	return ss.shared != nil && ss.shared[di]
+}
@@ -0,0 +1,366 @@
+package segmentedSlice
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Sort sorts the slice using the less function passed to NewSortable.
+// It is faster than sort.Sort(ss) since it works directly on the segments.
+func (ss *Slice) Sort() { ss.SortRange(0, ss.len) }
+
+// SortRange sorts the elements in [start, end) using the less function passed to NewSortable,
+// the rest of the slice is left untouched.
+func (ss *Slice) SortRange(start, end int) {
+	if ss.lessFn == nil {
+		panic("lessFn is nil, use NewSortable or SortFunc")
+	}
+	ss.checkRange(start, end)
+	ss.own(start, end)
+	ss.sorter(ss.lessFn).quickSort(start, end, maxDepth(end-start))
+}
+
+// SortFunc sorts the slice using less.
+// It uses an introsort (quicksort with a heapsort fallback and insertion sort for small ranges)
+// that works directly on the segments rather than going through sort.Interface.
+func (ss *Slice) SortFunc(less func(a, b interface{}) bool) {
+	ss.own(0, ss.len)
+	s := ss.sorter(less)
+	s.quickSort(0, ss.len, maxDepth(ss.len))
+}
+
+// InsertSortedBatch inserts vals into the sorted slice, keeping it sorted according to the less function passed to NewSortable.
+// The batch is sorted then merged into the slice in a single pass from the back, so every element is moved at most once.
+// Elements equal to existing ones are inserted after them, vals itself isn't modified.
+// If used on a sub-slice, it turns into an independent slice.
+func (ss *Slice) InsertSortedBatch(vals ...interface{}) {
+	if ss.lessFn == nil {
+		panic("lessFn is nil, use NewSortable")
+	}
+	if len(vals) == 0 {
+		return
+	}
+
+	batch := append([]interface{}(nil), vals...)
+	sort.Stable(&lessSlice{batch, ss.lessFn})
+
+	ss.Grow(len(batch))
+	i, j := ss.len-1, len(batch)-1
+	ss.len += len(batch)
+	ss.mods++
+	ss.own(0, ss.len)
+
+	for k := ss.len - 1; j >= 0; k-- {
+		if i >= 0 && ss.lessFn(batch[j], ss.load(*ss.ptrAt(i))) {
+			*ss.ptrAt(k) = *ss.ptrAt(i)
+			i--
+		} else {
+			*ss.ptrAt(k) = ss.store(batch[j])
+			j--
+		}
+	}
+}
+
+// MergeSorted merges ss and others, which must all be sorted, into a new sorted Slice using a k-way merge
+// with the less function passed to NewSortable. Equal elements keep their order, elements of ss come first
+// followed by the elements of others in the order they were passed.
+func (ss *Slice) MergeSorted(others ...*Slice) *Slice {
+	if ss.lessFn == nil {
+		panic("lessFn is nil, use NewSortable")
+	}
+
+	var (
+		nss   = ss.newEmpty()
+		h     = &mergeHeap{less: ss.lessFn}
+		total int
+	)
+
+	for i, s := range append([]*Slice{ss}, others...) {
+		if s.len == 0 {
+			continue
+		}
+		total += s.len
+		m := &mergeItem{it: NewIterVal(s, 0, s.len), src: i}
+		m.v = m.it.Next()
+		h.items = append(h.items, m)
+	}
+
+	nss.Grow(total)
+	heap.Init(h)
+	for h.Len() > 0 {
+		m := h.items[0]
+		nss.Append(m.v)
+		if m.it.More() {
+			m.v = m.it.Next()
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+
+	return nss
+}
+
+// IndexSorter adapts a Slice to external sorting algorithms that only need Len/Less/Swap (it implements sort.Interface)
+// or an index based compare function, without copying the data out of the slice.
+type IndexSorter struct {
+	s sorter
+	n int
+}
+
+// IndexSorter returns an IndexSorter over the slice using less, or the less function passed to NewSortable if less is nil.
+// The slice must not grow or shrink while the IndexSorter is used.
+// Example:
+// 	sort.Stable(ss.IndexSorter(nil))
+func (ss *Slice) IndexSorter(less func(a, b interface{}) bool) *IndexSorter {
+	if less == nil {
+		if less = ss.lessFn; less == nil {
+			panic("lessFn is nil, use NewSortable or pass less")
+		}
+	}
+	ss.own(0, ss.len)
+	return &IndexSorter{s: *ss.sorter(less), n: ss.len}
+}
+
+// Len returns the number of elements.
+func (is *IndexSorter) Len() int { return is.n }
+
+// Less reports whether the element at i should sort before the element at j.
+func (is *IndexSorter) Less(i, j int) bool { return is.s.lessAt(i, j) }
+
+// Swap swaps the elements at i and j.
+func (is *IndexSorter) Swap(i, j int) { is.s.swap(i, j) }
+
+// Compare returns -1 if the element at i sorts before the element at j, 1 if it sorts after and 0 otherwise.
+func (is *IndexSorter) Compare(i, j int) int {
+	switch {
+	case is.s.lessAt(i, j):
+		return -1
+	case is.s.lessAt(j, i):
+		return 1
+	}
+	return 0
+}
+
+func (ss *Slice) sorter(less func(a, b interface{}) bool) *sorter {
+	s := &sorter{
+		data:  ss.data,
+		shift: ss.shift,
+		mask:  ss.segLen,
+		base:  ss.baseIdx,
+		less:  func(a, b interface{}) bool { return less(ss.load(a), ss.load(b)) },
+	}
+	if ss.geo != nil {
+		s.geo = ss
+	}
+	return s
+}
+
+// sorter holds everything needed to translate an index to a segment slot without going through the Slice.
+type sorter struct {
+	data  [][]interface{}
+	shift uint
+	mask  int
+	base  int
+	less  func(a, b interface{}) bool
+
+	geo *Slice // set if the slice has geometric segments
+}
+
+func (s *sorter) at(i int) *interface{} {
+	i += s.base
+	if s.geo != nil {
+		di, si := s.geo.geoIndex(i)
+		return &s.data[di][si]
+	}
+	return &s.data[i>>s.shift][i&s.mask]
+}
+
+func (s *sorter) lessAt(i, j int) bool { return s.less(*s.at(i), *s.at(j)) }
+
+func (s *sorter) swap(i, j int) {
+	a, b := s.at(i), s.at(j)
+	*a, *b = *b, *a
+}
+
+func (s *sorter) quickSort(a, b, depth int) {
+	for b-a > 12 {
+		if depth == 0 {
+			s.heapSort(a, b)
+			return
+		}
+		depth--
+
+		p := s.partition(a, b)
+		// recurse into the smaller side to bound the stack depth
+		if p-a < b-p {
+			s.quickSort(a, p, depth)
+			a = p + 1
+		} else {
+			s.quickSort(p+1, b, depth)
+			b = p
+		}
+	}
+
+	if b-a > 1 {
+		s.insertionSort(a, b)
+	}
+}
+
+// partition partitions [a, b) around a median-of-three (or ninther for larger ranges) pivot
+// and returns the final position of the pivot.
+func (s *sorter) partition(a, b int) int {
+	m := int(uint(a+b) >> 1)
+	if b-a > 40 {
+		n := (b - a) / 8
+		s.medianOfThree(a, a+n, a+2*n)
+		s.medianOfThree(m, m-n, m+n)
+		s.medianOfThree(b-1, b-1-n, b-1-2*n)
+	}
+	s.medianOfThree(a, m, b-1)
+
+	pivot := *s.at(a)
+	i, j := a+1, b-1
+	for {
+		for i <= j && s.less(*s.at(i), pivot) {
+			i++
+		}
+		for i <= j && s.less(pivot, *s.at(j)) {
+			j--
+		}
+		if i >= j {
+			break
+		}
+		s.swap(i, j)
+		i++
+		j--
+	}
+	s.swap(a, j)
+	return j
+}
+
+// medianOfThree moves the median of the three values to m1, so that data[m0] <= data[m1] <= data[m2].
+func (s *sorter) medianOfThree(m1, m0, m2 int) {
+	if s.lessAt(m1, m0) {
+		s.swap(m1, m0)
+	}
+	if s.lessAt(m2, m1) {
+		s.swap(m2, m1)
+		if s.lessAt(m1, m0) {
+			s.swap(m1, m0)
+		}
+	}
+}
+
+func (s *sorter) insertionSort(a, b int) {
+	for i := a + 1; i < b; i++ {
+		for j := i; j > a && s.lessAt(j, j-1); j-- {
+			s.swap(j, j-1)
+		}
+	}
+}
+
+func (s *sorter) heapSort(a, b int) {
+	n := b - a
+	for i := (n - 1) / 2; i >= 0; i-- {
+		s.siftDown(i, n, a)
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		s.swap(a, a+i)
+		s.siftDown(0, i, a)
+	}
+}
+
+// siftDown implements the heap property on [lo, hi), offset by first.
+func (s *sorter) siftDown(lo, hi, first int) {
+	root := lo
+	for {
+		child := 2*root + 1
+		if child >= hi {
+			return
+		}
+		if child+1 < hi && s.lessAt(first+child, first+child+1) {
+			child++
+		}
+		if !s.lessAt(first+root, first+child) {
+			return
+		}
+		s.swap(first+root, first+child)
+		root = child
+	}
+}
+
+// maxDepth returns a threshold at which quicksort should switch to heapsort.
+func maxDepth(n int) (depth int) {
+	for i := n; i > 0; i >>= 1 {
+		depth++
+	}
+	return depth * 2
+}
+
+// Reverse reverses the order of the elements in place.
+func (ss *Slice) Reverse() {
+	if ss.len < 2 {
+		return
+	}
+
+	ss.own(0, ss.len)
+	ldi, lsi := ss.index(ss.baseIdx)
+	rdi, rsi := ss.index(ss.baseIdx + ss.len - 1)
+	l, r := ss.data[ldi], ss.data[rdi]
+	for n := ss.len / 2; n > 0; n-- {
+		l[lsi], r[rsi] = r[rsi], l[lsi]
+
+		if lsi++; lsi == len(l) {
+			ldi++
+			l, lsi = ss.data[ldi], 0
+		}
+
+		if rsi--; rsi < 0 {
+			rdi--
+			r = ss.data[rdi]
+			rsi = len(r) - 1
+		}
+	}
+}
+
+// lessSlice implements sort.Interface for a plain slice and a less function.
+type lessSlice struct {
+	s    []interface{}
+	less func(a, b interface{}) bool
+}
+
+func (ls *lessSlice) Len() int           { return len(ls.s) }
+func (ls *lessSlice) Less(i, j int) bool { return ls.less(ls.s[i], ls.s[j]) }
+func (ls *lessSlice) Swap(i, j int)      { ls.s[i], ls.s[j] = ls.s[j], ls.s[i] }
+
+type mergeItem struct {
+	it  Iterator
+	v   interface{}
+	src int
+}
+
+// mergeHeap implements heap.Interface over the heads of the slices being merged.
+type mergeHeap struct {
+	items []*mergeItem
+	less  func(a, b interface{}) bool
+}
+
+func (h *mergeHeap) Len() int      { return len(h.items) }
+func (h *mergeHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *mergeHeap) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if h.less(a.v, b.v) {
+		return true
+	}
+	return !h.less(b.v, a.v) && a.src < b.src
+}
+
+func (h *mergeHeap) Push(x interface{}) { h.items = append(h.items, x.(*mergeItem)) }
+func (h *mergeHeap) Pop() interface{} {
+	n := len(h.items) - 1
+	x := h.items[n]
+	h.items = h.items[:n]
+	return x
+}
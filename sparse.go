@@ -0,0 +1,174 @@
+package segmentedSlice
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SparseSlice is a slice with holes, it only stores the segments that hold at least one element,
+// so ID->record tables with large gaps between the IDs don't allocate the empty ranges.
+type SparseSlice struct {
+	segLen int
+	shift  uint
+	len    int
+	count  int
+	data   map[int]*sparseSegment
+}
+
+type sparseSegment struct {
+	vals []interface{}
+	set  []bool
+	n    int
+}
+
+// NewSparse returns a new SparseSlice with the specified segment length, it must be a power of two.
+func NewSparse(segLen int) *SparseSlice {
+	if !isPowerOfTwo(segLen) {
+		panic("segLen is not power of two")
+	}
+
+	return &SparseSlice{
+		segLen: segLen - 1,
+		shift:  findShift(segLen),
+		data:   make(map[int]*sparseSegment),
+	}
+}
+
+// SetAt sets the value at index i, allocating its segment if needed. It panics if i is negative.
+func (sp *SparseSlice) SetAt(i int, v interface{}) {
+	sp.checkIndex(i)
+	di, si := i>>sp.shift, i&sp.segLen
+	seg := sp.data[di]
+	if seg == nil {
+		seg = &sparseSegment{vals: make([]interface{}, sp.segLen+1), set: make([]bool, sp.segLen+1)}
+		sp.data[di] = seg
+	}
+
+	if !seg.set[si] {
+		seg.set[si] = true
+		seg.n++
+		sp.count++
+	}
+	seg.vals[si] = v
+
+	if i >= sp.len {
+		sp.len = i + 1
+	}
+}
+
+// GetOK returns the value at index i and true, or nil and false if it was never set (or deleted).
+func (sp *SparseSlice) GetOK(i int) (v interface{}, ok bool) {
+	if i < 0 {
+		return nil, false
+	}
+
+	seg := sp.data[i>>sp.shift]
+	if seg == nil || !seg.set[i&sp.segLen] {
+		return nil, false
+	}
+	return seg.vals[i&sp.segLen], true
+}
+
+// Get returns the value at index i or nil if it isn't set.
+func (sp *SparseSlice) Get(i int) interface{} {
+	v, _ := sp.GetOK(i)
+	return v
+}
+
+// Delete removes the value at index i and returns true if it was set, empty segments are released.
+func (sp *SparseSlice) Delete(i int) bool {
+	if i < 0 {
+		return false
+	}
+
+	di, si := i>>sp.shift, i&sp.segLen
+	seg := sp.data[di]
+	if seg == nil || !seg.set[si] {
+		return false
+	}
+
+	seg.vals[si], seg.set[si] = nil, false
+	seg.n--
+	sp.count--
+	if seg.n == 0 {
+		delete(sp.data, di)
+	}
+
+	if i == sp.len-1 {
+		sp.len = sp.lastIndex() + 1
+	}
+	return true
+}
+
+// Len returns the highest set index + 1.
+func (sp *SparseSlice) Len() int { return sp.len }
+
+// Count returns the number of set elements.
+func (sp *SparseSlice) Count() int { return sp.count }
+
+// Segments returns the number of allocated segments.
+func (sp *SparseSlice) Segments() int { return len(sp.data) }
+
+// ForEach loops over the set elements in index order and calls fn for each one.
+// If fn returns true, it breaks early and returns true otherwise returns false.
+func (sp *SparseSlice) ForEach(fn func(i int, v interface{}) (breakNow bool)) bool {
+	for _, di := range sp.segmentKeys() {
+		seg := sp.data[di]
+		for si, set := range seg.set {
+			if set && fn(di<<sp.shift+si, seg.vals[si]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Compact returns a Slice with the set elements in index order, dropping the holes.
+func (sp *SparseSlice) Compact() *Slice {
+	ss := New(sp.segLen + 1)
+	ss.Grow(sp.count)
+	sp.ForEach(func(_ int, v interface{}) (_ bool) {
+		ss.Append(v)
+		return
+	})
+	return ss
+}
+
+func (sp *SparseSlice) String() string {
+	return fmt.Sprintf("&SparseSlice{Len: %d, Count: %d, Segments: %d}", sp.len, sp.count, len(sp.data))
+}
+
+func (sp *SparseSlice) checkIndex(i int) {
+	if i < 0 || i == maxInt {
+		panic(fmt.Sprintf("index out of range [%d]", i))
+	}
+}
+
+// lastIndex returns the highest set index or -1 if the slice is empty.
+func (sp *SparseSlice) lastIndex() int {
+	last := -1
+	for di := range sp.data {
+		if di > last {
+			last = di
+		}
+	}
+	if last == -1 {
+		return -1
+	}
+
+	seg := sp.data[last]
+	for si := len(seg.set) - 1; ; si-- {
+		if seg.set[si] {
+			return last<<sp.shift + si
+		}
+	}
+}
+
+func (sp *SparseSlice) segmentKeys() []int {
+	keys := make([]int, 0, len(sp.data))
+	for di := range sp.data {
+		keys = append(keys, di)
+	}
+	sort.Ints(keys)
+	return keys
+}
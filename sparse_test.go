@@ -0,0 +1,50 @@
+package segmentedSlice
+
+import "testing"
+
+func TestSparseSlice(t *testing.T) {
+	sp := NewSparse(8)
+	sp.SetAt(3, "a")
+	sp.SetAt(1000000, "b")
+	sp.SetAt(1000001, nil)
+
+	if sp.Len() != 1000002 || sp.Count() != 3 || sp.Segments() != 2 {
+		t.Fatalf("unexpected slice: %v", sp)
+	}
+	if v, ok := sp.GetOK(3); !ok || v != "a" {
+		t.Fatalf("expected a, got %v %v", v, ok)
+	}
+	if v, ok := sp.GetOK(1000001); !ok || v != nil {
+		t.Fatalf("expected a set nil, got %v %v", v, ok)
+	}
+	if _, ok := sp.GetOK(4); ok {
+		t.Fatal("unexpected value at 4")
+	}
+	if _, ok := sp.GetOK(-1); ok {
+		t.Fatal("unexpected value at -1")
+	}
+
+	var idx []int
+	sp.ForEach(func(i int, _ interface{}) (_ bool) {
+		idx = append(idx, i)
+		return
+	})
+	if len(idx) != 3 || idx[0] != 3 || idx[2] != 1000001 {
+		t.Fatalf("unexpected ForEach order: %v", idx)
+	}
+
+	if c := sp.Compact(); c.Len() != 3 || c.Get(1) != "b" {
+		t.Fatalf("unexpected Compact: %v", c)
+	}
+
+	if !sp.Delete(1000001) || sp.Delete(1000001) || sp.Len() != 1000001 {
+		t.Fatalf("unexpected delete: %v", sp)
+	}
+	if !sp.Delete(1000000) || sp.Len() != 4 || sp.Segments() != 1 || sp.Count() != 1 {
+		t.Fatalf("unexpected delete: %v", sp)
+	}
+	sp.Delete(3)
+	if sp.Len() != 0 || sp.Segments() != 0 {
+		t.Fatalf("unexpected delete: %v", sp)
+	}
+}
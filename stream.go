@@ -0,0 +1,114 @@
+package segmentedSlice
+
+import "io"
+
+// StreamChunks calls fn with consecutive chunks of at most chunkSize elements and stops at the first error,
+// it is meant to send a large slice over a stream in bounded messages.
+// The chunk is reused between calls, so fn must not retain it.
+func (ss *Slice) StreamChunks(fn func(chunk []interface{}) error, chunkSize int) (err error) {
+	if chunkSize < 1 {
+		panic("chunkSize must be > 0")
+	}
+	if ss.len == 0 {
+		return
+	}
+
+	if chunkSize > ss.len {
+		chunkSize = ss.len
+	}
+
+	buf := make([]interface{}, 0, chunkSize)
+	ss.forEachSeg(0, ss.len, func(_ int, seg []interface{}) bool {
+		for len(seg) > 0 {
+			n := copy(buf[len(buf):cap(buf)], seg)
+			ss.loadAll(buf[len(buf) : len(buf)+n])
+			buf, seg = buf[:len(buf)+n], seg[n:]
+			if len(buf) < cap(buf) {
+				continue
+			}
+			if err = fn(buf); err != nil {
+				return true
+			}
+			buf = buf[:0]
+		}
+		return false
+	})
+
+	if err == nil && len(buf) > 0 {
+		err = fn(buf)
+	}
+
+	return
+}
+
+// AppendChunks appends the chunks returned by recv until it returns an error,
+// it is the receiving side of StreamChunks. io.EOF signals the end of the stream and isn't returned.
+// Example:
+// 	err := ss.AppendChunks(func() ([]interface{}, error) {
+// 		msg, err := stream.Recv()
+// 		if err != nil {
+// 			return nil, err
+// 		}
+// 		return msg.Values, nil
+// 	})
+func (ss *Slice) AppendChunks(recv func() (chunk []interface{}, err error)) error {
+	for {
+		chunk, err := recv()
+		if len(chunk) > 0 {
+			ss.Append(chunk...)
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// EncodeJSONBatched writes the JSON encoding of the slice to w, writing and flushing every time
+// at least maxBytesPerFlush bytes have been buffered, so huge slices stream in bounded pieces
+// (e.g. through proxies that buffer whole responses).
+// w is flushed if it implements Flush() error (e.g. *bufio.Writer) or Flush() (e.g. http.Flusher).
+func (ss *Slice) EncodeJSONBatched(w io.Writer, maxBytesPerFlush int) (err error) {
+	if maxBytesPerFlush < 1 {
+		panic("maxBytesPerFlush must be > 0")
+	}
+
+	b := make([]byte, 0, maxBytesPerFlush+64)
+	flush := func() error {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+		b = b[:0]
+
+		switch f := w.(type) {
+		case interface{ Flush() error }:
+			return f.Flush()
+		case interface{ Flush() }:
+			f.Flush()
+		}
+		return nil
+	}
+
+	b = append(b, '[')
+	ss.ForEach(func(i int, v interface{}) bool {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if b, err = appendJSONValue(b, v); err != nil {
+			return true
+		}
+		if len(b) >= maxBytesPerFlush {
+			err = flush()
+		}
+		return err != nil
+	})
+	if err != nil {
+		return
+	}
+
+	b = append(b, ']')
+	return flush()
+}
@@ -0,0 +1,256 @@
+package segmentedSlice
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// SyncSlice wraps a Slice with a RWMutex, so it can be used from multiple goroutines.
+// Reads take a read lock and writes take a write lock, use Do to run several operations under a single lock.
+type SyncSlice struct {
+	mu sync.RWMutex
+	ss *Slice
+}
+
+// NewSyncSlice returns a SyncSlice wrapping ss, ss must not be used directly afterwards.
+// If ss is nil, a new Slice with the DefaultSegmentLen is used.
+func NewSyncSlice(ss *Slice) *SyncSlice {
+	if ss == nil {
+		ss = New(DefaultSegmentLen)
+	}
+	return &SyncSlice{ss: ss}
+}
+
+// Get returns the item at the specified index, it panics if i is out of range.
+func (s *SyncSlice) Get(i int) interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ss.Get(i)
+}
+
+// Len returns the number of elements in the slice.
+func (s *SyncSlice) Len() int {
+	s.mu.RLock()
+	n := s.ss.Len()
+	s.mu.RUnlock()
+	return n
+}
+
+// ForEach calls fn for each element under a read lock, fn must not modify the slice.
+func (s *SyncSlice) ForEach(fn func(i int, v interface{}) (breakNow bool)) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ss.ForEach(fn)
+}
+
+// Set sets the value at the specified index, it panics if i is out of range.
+func (s *SyncSlice) Set(i int, v interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ss.Set(i, v)
+}
+
+// Append appends vals to the slice.
+func (s *SyncSlice) Append(vals ...interface{}) {
+	s.mu.Lock()
+	s.ss.Append(vals...)
+	s.mu.Unlock()
+}
+
+// Pop deletes and returns the last item in the slice, ok is false if the slice is empty.
+func (s *SyncSlice) Pop() (v interface{}, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ss.Len() == 0 {
+		return nil, false
+	}
+	return s.ss.Pop(), true
+}
+
+// Do calls fn with the underlying slice under a write lock, fn must not keep a reference to the slice.
+// Example:
+// 	s.Do(func(ss *Slice) {
+// 		if ss.Len() < max {
+// 			ss.Append(v)
+// 		}
+// 	})
+func (s *SyncSlice) Do(fn func(ss *Slice)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.ss)
+}
+
+// DoRead calls fn with the underlying slice under a read lock, fn must not modify or keep a reference to the slice.
+func (s *SyncSlice) DoRead(fn func(ss *Slice)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.ss)
+}
+
+// ConcurrentAppender lets multiple goroutines load data in parallel without sharing a lock,
+// every goroutine appends to its own AppenderShard and Finish merges the shards into a single Slice.
+type ConcurrentAppender struct {
+	mu       sync.Mutex
+	segLen   int
+	opts     []Option
+	shards   []*AppenderShard
+	finished bool
+}
+
+// AppenderShard is a single goroutine's part of a ConcurrentAppender, it must not be used concurrently.
+type AppenderShard struct {
+	ss *Slice
+}
+
+// NewConcurrentAppender returns a ConcurrentAppender, the shards and the final Slice are created with New(segLen, opts...).
+// Example:
+// 	ca := NewConcurrentAppender(1024)
+// 	for _, part := range parts {
+// 		go func(sh *AppenderShard, part []interface{}) {
+// 			defer wg.Done()
+// 			sh.Append(part...)
+// 		}(ca.Shard(), part)
+// 	}
+// 	wg.Wait()
+// 	ss := ca.Finish()
+func NewConcurrentAppender(segLen int, opts ...Option) *ConcurrentAppender {
+	if !isPowerOfTwo(segLen) {
+		panic("segLen is not power of two")
+	}
+	return &ConcurrentAppender{segLen: segLen, opts: opts}
+}
+
+// Shard returns a new shard, it is safe to call concurrently.
+func (ca *ConcurrentAppender) Shard() *AppenderShard {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	if ca.finished {
+		panic("appender is finished")
+	}
+
+	sh := &AppenderShard{ss: New(ca.segLen, ca.opts...)}
+	ca.shards = append(ca.shards, sh)
+	return sh
+}
+
+// Append appends vals to the shard.
+func (sh *AppenderShard) Append(vals ...interface{}) {
+	if sh.ss == nil {
+		panic("appender is finished")
+	}
+	sh.ss.Append(vals...)
+}
+
+// Len returns the number of elements appended to the shard.
+func (sh *AppenderShard) Len() int {
+	if sh.ss == nil {
+		return 0
+	}
+	return sh.ss.Len()
+}
+
+// Finish merges all the shards into a single Slice, the full segments of every shard are handed over by pointer
+// and only the partially filled tail segments are copied.
+// Elements appended to the same shard keep their relative order, but the order across shards is unspecified.
+// Finish must be called after all the goroutines are done appending, the shards can't be used afterwards.
+func (ca *ConcurrentAppender) Finish() *Slice {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	if ca.finished {
+		panic("appender is finished")
+	}
+	ca.finished = true
+
+	var (
+		nss    = New(ca.segLen, ca.opts...)
+		segLen = nss.segLen + 1
+		total  int
+		tails  []*Slice
+	)
+
+	for _, sh := range ca.shards {
+		ss := sh.ss
+		total += ss.len
+		full := ss.len >> ss.shift
+		if nss.geo != nil { // the shards' segments don't fit nss's layout
+			full = 0
+		}
+		nss.data = append(nss.data, ss.data[:full]...)
+		nss.len += full * segLen
+		if ss.len > full*segLen {
+			tails = append(tails, ss.Slice(full*segLen, ss.len))
+		}
+		sh.ss = nil
+	}
+	nss.cap = nss.len
+
+	nss.Grow(total - nss.len)
+	nss.own(nss.len, total)
+	for _, tail := range tails {
+		tail.forEachSeg(0, tail.len, func(_ int, seg []interface{}) (_ bool) {
+			for _, v := range seg {
+				*nss.ptrAt(nss.len) = v
+				nss.len++
+			}
+			return
+		})
+	}
+
+	ca.shards = nil
+	return nss
+}
+
+// AtomicSlice is an append-only Slice for a single writer and many readers,
+// every Append publishes the new length and segment directory atomically so readers never take a lock.
+// Only one goroutine may call Append at a time, any number of goroutines can call Len, Get and Load.
+type AtomicSlice struct {
+	w     *Slice
+	tmpl  *Slice
+	state atomic.Value // *atomicState
+}
+
+type atomicState struct {
+	data [][]interface{}
+	len  int
+}
+
+// NewAtomic returns an AtomicSlice, the elements are stored in a Slice created with New(segLen, opts...).
+func NewAtomic(segLen int, opts ...Option) *AtomicSlice {
+	w := New(segLen, opts...)
+	a := &AtomicSlice{w: w, tmpl: w.newEmpty()}
+	a.state.Store(&atomicState{})
+	return a
+}
+
+// Append appends vals and publishes them to the readers, it must only be called by the writer goroutine.
+// Appending several values in one call only publishes once.
+func (a *AtomicSlice) Append(vals ...interface{}) {
+	a.w.Append(vals...)
+	a.state.Store(&atomicState{data: a.w.data, len: a.w.len})
+}
+
+// Len returns the number of published elements.
+func (a *AtomicSlice) Len() int { return a.load().len }
+
+// Get returns the published element at the specified index, it panics if i is out of range.
+func (a *AtomicSlice) Get(i int) interface{} {
+	st := a.load()
+	if uint(i) >= uint(st.len) {
+		panic(fmt.Sprintf("index out of range [%d] with length %d", i, st.len))
+	}
+	di, si := a.tmpl.index(i)
+	return a.tmpl.load(st.data[di][si])
+}
+
+// Load returns a frozen Slice holding the elements published so far, it can be iterated, searched,
+// marshaled and so on without any locking and isn't affected by later appends.
+func (a *AtomicSlice) Load() *Slice {
+	st := a.load()
+	ss := *a.tmpl
+	ss.data, ss.len, ss.cap = st.data, st.len, ss.segStart(len(st.data))
+	ss.frozen = true
+	return &ss
+}
+
+func (a *AtomicSlice) load() *atomicState { return a.state.Load().(*atomicState) }
@@ -0,0 +1,146 @@
+package segmentedSlice
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSyncSlice(t *testing.T) {
+	var (
+		s  = NewSyncSlice(New(4))
+		wg sync.WaitGroup
+	)
+
+	for w := 0; w < 8; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				s.Append(w*100 + i)
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if n := s.Len(); n > 0 {
+					s.Get(n - 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if s.Len() != 800 {
+		t.Fatalf("expected 800 elements, got %d", s.Len())
+	}
+
+	sum := 0
+	s.ForEach(func(_ int, v interface{}) (_ bool) {
+		sum += v.(int)
+		return
+	})
+	if exp := 800 * 799 / 2; sum != exp {
+		t.Fatalf("expected %d, got %d", exp, sum)
+	}
+
+	s.Do(func(ss *Slice) {
+		for ss.Len() > 1 {
+			ss.Pop()
+		}
+	})
+	if _, ok := s.Pop(); !ok {
+		t.Fatal("expected an element")
+	}
+	if _, ok := s.Pop(); ok {
+		t.Fatal("expected an empty slice")
+	}
+}
+
+func TestConcurrentAppender(t *testing.T) {
+	var (
+		ca = NewConcurrentAppender(8)
+		wg sync.WaitGroup
+	)
+
+	for w := 0; w < 5; w++ {
+		wg.Add(1)
+		go func(sh *AppenderShard, w int) {
+			defer wg.Done()
+			for i := 0; i < 21*w; i++ {
+				sh.Append(w*1000 + i)
+			}
+		}(ca.Shard(), w)
+	}
+	wg.Wait()
+
+	ss := ca.Finish()
+	if ss.Len() != 210 {
+		t.Fatalf("expected 210 elements, got %d", ss.Len())
+	}
+
+	next := map[int]int{}
+	ss.ForEach(func(_ int, v interface{}) (_ bool) {
+		w, i := v.(int)/1000, v.(int)%1000
+		if i != next[w] {
+			t.Fatalf("shard %d: expected %d, got %d", w, next[w], i)
+		}
+		next[w]++
+		return
+	})
+
+	ss.Append(1)
+	if ss.Len() != 211 || ss.Get(210) != 1 {
+		t.Fatalf("can't append to the merged slice: %#v", ss)
+	}
+}
+
+func TestAtomicSlice(t *testing.T) {
+	var (
+		a    = NewAtomic(4)
+		wg   sync.WaitGroup
+		done = make(chan struct{})
+	)
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				ss := a.Load()
+				ss.ForEach(func(i int, v interface{}) (_ bool) {
+					if v != i {
+						t.Errorf("expected %d, got %v", i, v)
+					}
+					return
+				})
+				if n := a.Len(); n > 0 && a.Get(n-1) != n-1 {
+					t.Errorf("expected %d, got %v", n-1, a.Get(n-1))
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 1000; i += 2 {
+		a.Append(i, i+1)
+	}
+	close(done)
+	wg.Wait()
+
+	if ss := a.Load(); ss.Len() != 1000 || !ss.Frozen() {
+		t.Fatalf("unexpected slice: %d %v", ss.Len(), ss.Frozen())
+	}
+}
+
+func TestAtomicSliceSegLen1(t *testing.T) {
+	a := NewAtomic(1)
+	a.Append(1, 2, 3)
+	if a.Get(1) != 2 || a.Get(2) != 3 || a.Load().String() != "[1, 2, 3]" {
+		t.Fatalf("unexpected slice: %v", a.Load())
+	}
+}
@@ -0,0 +1,106 @@
+package segmentedSlice
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// SegLenResult holds the measurements for a single segment length, see TuneSegLen.
+type SegLenResult struct {
+	SegLen   int
+	Len      int
+	Segments int
+
+	Append time.Duration // time spent in sampleAppend
+	Get    time.Duration // time to Get every element
+	Sort   time.Duration // time to Sort, 0 if the slice isn't sortable
+}
+
+// Total returns the sum of all the measurements.
+func (r SegLenResult) Total() time.Duration { return r.Append + r.Get + r.Sort }
+
+// Report is returned by TuneSegLen.
+type Report struct {
+	Results []SegLenResult
+	// Best is the segment length with the lowest total time.
+	Best int
+}
+
+func (r Report) String() string {
+	var b bytes.Buffer
+	for _, res := range r.Results {
+		best := ""
+		if res.SegLen == r.Best {
+			best = " *"
+		}
+		fmt.Fprintf(&b, "segLen %6d: append %v, get %v, sort %v, total %v%s\n",
+			res.SegLen, res.Append, res.Get, res.Sort, res.Total(), best)
+	}
+	return b.String()
+}
+
+// DefaultTuneCandidates is used by TuneSegLen if candidates is empty.
+var DefaultTuneCandidates = []int{32, 64, 128, 256, 512, 1024, 2048, 4096, 8192}
+
+// TuneSegLen measures the cost of appending, reading and sorting for every candidate segment length
+// on the caller's workload and reports the fastest one.
+// sampleAppend is called once per candidate with a new slice created with New(segLen, opts...) and must fill it
+// the same way every time. Sorting is only measured if opts includes WithLess.
+// Example:
+// 	r := TuneSegLen(func(ss *Slice) {
+// 		for _, v := range sample {
+// 			ss.Append(v)
+// 		}
+// 	}, nil, WithLess(lessFn))
+// 	log.Println(r)
+// 	ss := New(r.Best)
+func TuneSegLen(sampleAppend func(ss *Slice), candidates []int, opts ...Option) Report {
+	if len(candidates) == 0 {
+		candidates = DefaultTuneCandidates
+	}
+
+	var r Report
+	for _, segLen := range candidates {
+		var (
+			ss    = New(segLen, opts...)
+			res   = SegLenResult{SegLen: segLen}
+			start = time.Now()
+		)
+
+		sampleAppend(ss)
+		res.Append = time.Since(start)
+
+		start = time.Now()
+		for i := 0; i < ss.Len(); i++ {
+			tuneSink = ss.Get(i)
+		}
+		res.Get = time.Since(start)
+
+		if ss.lessFn != nil {
+			start = time.Now()
+			ss.Sort()
+			res.Sort = time.Since(start)
+		}
+
+		res.Len, res.Segments = ss.Len(), ss.Segments()
+		if len(r.Results) == 0 || res.Total() < r.bestTotal() {
+			r.Best = segLen
+		}
+		r.Results = append(r.Results, res)
+	}
+
+	return r
+}
+
+func (r Report) bestTotal() time.Duration {
+	for _, res := range r.Results {
+		if res.SegLen == r.Best {
+			return res.Total()
+		}
+	}
+	return 0
+}
+
+// tuneSink keeps the compiler from optimizing away the measured reads.
+var tuneSink interface{}
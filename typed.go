@@ -0,0 +1,91 @@
+package segmentedSlice
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// GetInt returns the element at index i as an int, it converts any integer type, json.Number and
+// float64 values without a fractional part (the way encoding/json decodes numbers).
+// It returns false if the element can't be converted, and panics if i is out of range.
+func (ss *Slice) GetInt(i int) (int, bool) {
+	n, ok := toInt64(ss.Get(i))
+	if !ok || int64(int(n)) != n {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// GetInt64 is like GetInt but returns an int64.
+func (ss *Slice) GetInt64(i int) (int64, bool) { return toInt64(ss.Get(i)) }
+
+// GetFloat64 returns the element at index i as a float64, it converts any integer or float type and json.Number.
+// It returns false if the element can't be converted, and panics if i is out of range.
+func (ss *Slice) GetFloat64(i int) (float64, bool) {
+	switch v := ss.Get(i).(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		if n, ok := toInt64(v); ok {
+			return float64(n), true
+		}
+	}
+	return 0, false
+}
+
+// GetString returns the element at index i if it is a string, it panics if i is out of range.
+func (ss *Slice) GetString(i int) (string, bool) {
+	s, ok := ss.Get(i).(string)
+	return s, ok
+}
+
+// GetBool returns the element at index i if it is a bool, it panics if i is out of range.
+func (ss *Slice) GetBool(i int) (bool, bool) {
+	b, ok := ss.Get(i).(bool)
+	return b, ok
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), uint64(v) <= math.MaxInt64
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	case float32:
+		return floatToInt64(float64(v))
+	case float64:
+		return floatToInt64(v)
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func floatToInt64(f float64) (int64, bool) {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
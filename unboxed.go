@@ -0,0 +1,51 @@
+package segmentedSlice
+
+//go:generate go run gen_unboxed.go
+
+// segIndex is the indexing engine shared by the unboxed slices (IntSlice, Float64Slice, StringSlice, ByteSlice
+// and RawSlice), they store their elements in typed segments so the values aren't boxed in interfaces.
+// The typed methods are generated from the template in gen_unboxed.go.
+type segIndex struct {
+	len    int
+	segLen int
+	shift  uint
+}
+
+func newSegIndex(segLen int) segIndex {
+	if !isPowerOfTwo(segLen) {
+		panic("segLen is not power of two")
+	}
+	return segIndex{segLen: segLen - 1, shift: findShift(segLen)}
+}
+
+// Len returns the number of elements in the slice.
+func (x *segIndex) Len() int { return x.len }
+
+func (x *segIndex) index(i int) (int, int) {
+	return i >> x.shift, i & x.segLen
+}
+
+func (x *segIndex) checkIndex(i int) {
+	if uint(i) >= uint(x.len) {
+		panic(ErrIndexOutOfRange{i, x.len}.Error())
+	}
+}
+
+// forEachSeg calls fn with the index, the offset of the first element and the number of elements in use
+// of each segment holding elements, segs is the number of allocated segments.
+func (x *segIndex) forEachSeg(segs int, fn func(di, off, n int) (breakNow bool)) bool {
+	for di := 0; di < segs; di++ {
+		off := di << x.shift
+		if off >= x.len {
+			break
+		}
+		n := x.len - off
+		if n > x.segLen+1 {
+			n = x.segLen + 1
+		}
+		if fn(di, off, n) {
+			return true
+		}
+	}
+	return false
+}
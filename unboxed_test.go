@@ -0,0 +1,61 @@
+package segmentedSlice
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestUnboxed(t *testing.T) {
+	is := NewIntSlice(4)
+	for i := 0; i < 10; i++ {
+		is.Append(i)
+	}
+	is.Append(10, 11, 12)
+	is.Set(0, -1)
+	if is.Len() != 13 || is.Cap() != 16 || is.Get(0) != -1 || is.Get(12) != 12 {
+		t.Fatalf("unexpected IntSlice: %v", is)
+	}
+	if v := is.Pop(); v != 12 || is.Len() != 12 || is.data[3][0] != 0 {
+		t.Fatalf("unexpected Pop: %v %v", v, is)
+	}
+	for i := 0; i < 5; i++ {
+		is.Pop()
+	}
+	if s := is.String(); s != "[-1 1 2 3 4 5 6]" {
+		t.Fatalf("unexpected IntSlice after Pop: %s", s)
+	}
+
+	sum := 0
+	is.ForEach(func(i, v int) (_ bool) {
+		sum += v
+		return
+	})
+	if sum != 20 {
+		t.Fatalf("unexpected sum: %d", sum)
+	}
+
+	fs := NewFloat64Slice(2)
+	fs.Append(0.5, 1.5, 2.5)
+	if s := fs.String(); s != "[0.5 1.5 2.5]" {
+		t.Fatalf("unexpected Float64Slice: %s", s)
+	}
+
+	ss := NewStringSlice(2)
+	ss.Append("a", "b", "c")
+	if ss.Pop() != "c" || fmt.Sprint(ss.ToSlice()) != "[a b]" {
+		t.Fatalf("unexpected StringSlice: %v", ss)
+	}
+
+	bs := NewByteSlice(8)
+	bs.Append([]byte("hello, world")...)
+	if string(bs.ToSlice()) != "hello, world" || bs.Get(7) != 'w' {
+		t.Fatalf("unexpected ByteSlice: %v", bs)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	NewIntSlice(4).Pop()
+}
@@ -0,0 +1,305 @@
+// Code generated by gen_unboxed.go; DO NOT EDIT.
+
+package segmentedSlice
+
+import "fmt"
+
+// IntSlice is a segmented slice of int backed by []int segments.
+type IntSlice struct {
+	segIndex
+	data [][]int
+}
+
+// NewIntSlice returns a new IntSlice with the specified segment length, it must be a power of two.
+func NewIntSlice(segLen int) *IntSlice {
+	return &IntSlice{segIndex: newSegIndex(segLen)}
+}
+
+// Append appends vals to the slice.
+func (s *IntSlice) Append(vals ...int) {
+	for len(vals) > 0 {
+		di, si := s.index(s.len)
+		if di == len(s.data) {
+			s.data = append(s.data, make([]int, s.segLen+1))
+		}
+		n := copy(s.data[di][si:], vals)
+		vals, s.len = vals[n:], s.len+n
+	}
+}
+
+// Get returns the item at the specified index, it panics if i is out of range.
+func (s *IntSlice) Get(i int) int {
+	s.checkIndex(i)
+	di, si := s.index(i)
+	return s.data[di][si]
+}
+
+// Set sets the value at the specified index, it panics if i is out of range.
+func (s *IntSlice) Set(i int, v int) {
+	s.checkIndex(i)
+	di, si := s.index(i)
+	s.data[di][si] = v
+}
+
+// Pop deletes and returns the last item in the slice, it panics if the slice is empty.
+func (s *IntSlice) Pop() (v int) {
+	s.checkIndex(s.len - 1)
+	s.len--
+	di, si := s.index(s.len)
+	var zero int
+	v, s.data[di][si] = s.data[di][si], zero
+	return
+}
+
+// Cap returns the max number of elements the slice can hold before growing.
+func (s *IntSlice) Cap() int { return len(s.data) * (s.segLen + 1) }
+
+// ForEach loops over the slice and calls fn for each element.
+// If fn returns true, it breaks early and returns true otherwise returns false.
+func (s *IntSlice) ForEach(fn func(i int, v int) (breakNow bool)) bool {
+	return s.forEachSeg(len(s.data), func(di, off, n int) bool {
+		for si, v := range s.data[di][:n] {
+			if fn(off+si, v) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ToSlice returns the elements of the slice as a plain []int.
+func (s *IntSlice) ToSlice() []int {
+	out := make([]int, 0, s.len)
+	s.forEachSeg(len(s.data), func(di, _, n int) (_ bool) {
+		out = append(out, s.data[di][:n]...)
+		return
+	})
+	return out
+}
+
+func (s *IntSlice) String() string { return fmt.Sprint(s.ToSlice()) }
+
+// Float64Slice is a segmented slice of float64 backed by []float64 segments.
+type Float64Slice struct {
+	segIndex
+	data [][]float64
+}
+
+// NewFloat64Slice returns a new Float64Slice with the specified segment length, it must be a power of two.
+func NewFloat64Slice(segLen int) *Float64Slice {
+	return &Float64Slice{segIndex: newSegIndex(segLen)}
+}
+
+// Append appends vals to the slice.
+func (s *Float64Slice) Append(vals ...float64) {
+	for len(vals) > 0 {
+		di, si := s.index(s.len)
+		if di == len(s.data) {
+			s.data = append(s.data, make([]float64, s.segLen+1))
+		}
+		n := copy(s.data[di][si:], vals)
+		vals, s.len = vals[n:], s.len+n
+	}
+}
+
+// Get returns the item at the specified index, it panics if i is out of range.
+func (s *Float64Slice) Get(i int) float64 {
+	s.checkIndex(i)
+	di, si := s.index(i)
+	return s.data[di][si]
+}
+
+// Set sets the value at the specified index, it panics if i is out of range.
+func (s *Float64Slice) Set(i int, v float64) {
+	s.checkIndex(i)
+	di, si := s.index(i)
+	s.data[di][si] = v
+}
+
+// Pop deletes and returns the last item in the slice, it panics if the slice is empty.
+func (s *Float64Slice) Pop() (v float64) {
+	s.checkIndex(s.len - 1)
+	s.len--
+	di, si := s.index(s.len)
+	var zero float64
+	v, s.data[di][si] = s.data[di][si], zero
+	return
+}
+
+// Cap returns the max number of elements the slice can hold before growing.
+func (s *Float64Slice) Cap() int { return len(s.data) * (s.segLen + 1) }
+
+// ForEach loops over the slice and calls fn for each element.
+// If fn returns true, it breaks early and returns true otherwise returns false.
+func (s *Float64Slice) ForEach(fn func(i int, v float64) (breakNow bool)) bool {
+	return s.forEachSeg(len(s.data), func(di, off, n int) bool {
+		for si, v := range s.data[di][:n] {
+			if fn(off+si, v) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ToSlice returns the elements of the slice as a plain []float64.
+func (s *Float64Slice) ToSlice() []float64 {
+	out := make([]float64, 0, s.len)
+	s.forEachSeg(len(s.data), func(di, _, n int) (_ bool) {
+		out = append(out, s.data[di][:n]...)
+		return
+	})
+	return out
+}
+
+func (s *Float64Slice) String() string { return fmt.Sprint(s.ToSlice()) }
+
+// StringSlice is a segmented slice of string backed by []string segments.
+type StringSlice struct {
+	segIndex
+	data [][]string
+}
+
+// NewStringSlice returns a new StringSlice with the specified segment length, it must be a power of two.
+func NewStringSlice(segLen int) *StringSlice {
+	return &StringSlice{segIndex: newSegIndex(segLen)}
+}
+
+// Append appends vals to the slice.
+func (s *StringSlice) Append(vals ...string) {
+	for len(vals) > 0 {
+		di, si := s.index(s.len)
+		if di == len(s.data) {
+			s.data = append(s.data, make([]string, s.segLen+1))
+		}
+		n := copy(s.data[di][si:], vals)
+		vals, s.len = vals[n:], s.len+n
+	}
+}
+
+// Get returns the item at the specified index, it panics if i is out of range.
+func (s *StringSlice) Get(i int) string {
+	s.checkIndex(i)
+	di, si := s.index(i)
+	return s.data[di][si]
+}
+
+// Set sets the value at the specified index, it panics if i is out of range.
+func (s *StringSlice) Set(i int, v string) {
+	s.checkIndex(i)
+	di, si := s.index(i)
+	s.data[di][si] = v
+}
+
+// Pop deletes and returns the last item in the slice, it panics if the slice is empty.
+func (s *StringSlice) Pop() (v string) {
+	s.checkIndex(s.len - 1)
+	s.len--
+	di, si := s.index(s.len)
+	var zero string
+	v, s.data[di][si] = s.data[di][si], zero
+	return
+}
+
+// Cap returns the max number of elements the slice can hold before growing.
+func (s *StringSlice) Cap() int { return len(s.data) * (s.segLen + 1) }
+
+// ForEach loops over the slice and calls fn for each element.
+// If fn returns true, it breaks early and returns true otherwise returns false.
+func (s *StringSlice) ForEach(fn func(i int, v string) (breakNow bool)) bool {
+	return s.forEachSeg(len(s.data), func(di, off, n int) bool {
+		for si, v := range s.data[di][:n] {
+			if fn(off+si, v) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ToSlice returns the elements of the slice as a plain []string.
+func (s *StringSlice) ToSlice() []string {
+	out := make([]string, 0, s.len)
+	s.forEachSeg(len(s.data), func(di, _, n int) (_ bool) {
+		out = append(out, s.data[di][:n]...)
+		return
+	})
+	return out
+}
+
+func (s *StringSlice) String() string { return fmt.Sprint(s.ToSlice()) }
+
+// ByteSlice is a segmented slice of byte backed by []byte segments.
+type ByteSlice struct {
+	segIndex
+	data [][]byte
+}
+
+// NewByteSlice returns a new ByteSlice with the specified segment length, it must be a power of two.
+func NewByteSlice(segLen int) *ByteSlice {
+	return &ByteSlice{segIndex: newSegIndex(segLen)}
+}
+
+// Append appends vals to the slice.
+func (s *ByteSlice) Append(vals ...byte) {
+	for len(vals) > 0 {
+		di, si := s.index(s.len)
+		if di == len(s.data) {
+			s.data = append(s.data, make([]byte, s.segLen+1))
+		}
+		n := copy(s.data[di][si:], vals)
+		vals, s.len = vals[n:], s.len+n
+	}
+}
+
+// Get returns the item at the specified index, it panics if i is out of range.
+func (s *ByteSlice) Get(i int) byte {
+	s.checkIndex(i)
+	di, si := s.index(i)
+	return s.data[di][si]
+}
+
+// Set sets the value at the specified index, it panics if i is out of range.
+func (s *ByteSlice) Set(i int, v byte) {
+	s.checkIndex(i)
+	di, si := s.index(i)
+	s.data[di][si] = v
+}
+
+// Pop deletes and returns the last item in the slice, it panics if the slice is empty.
+func (s *ByteSlice) Pop() (v byte) {
+	s.checkIndex(s.len - 1)
+	s.len--
+	di, si := s.index(s.len)
+	var zero byte
+	v, s.data[di][si] = s.data[di][si], zero
+	return
+}
+
+// Cap returns the max number of elements the slice can hold before growing.
+func (s *ByteSlice) Cap() int { return len(s.data) * (s.segLen + 1) }
+
+// ForEach loops over the slice and calls fn for each element.
+// If fn returns true, it breaks early and returns true otherwise returns false.
+func (s *ByteSlice) ForEach(fn func(i int, v byte) (breakNow bool)) bool {
+	return s.forEachSeg(len(s.data), func(di, off, n int) bool {
+		for si, v := range s.data[di][:n] {
+			if fn(off+si, v) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ToSlice returns the elements of the slice as a plain []byte.
+func (s *ByteSlice) ToSlice() []byte {
+	out := make([]byte, 0, s.len)
+	s.forEachSeg(len(s.data), func(di, _, n int) (_ bool) {
+		out = append(out, s.data[di][:n]...)
+		return
+	})
+	return out
+}
+
+func (s *ByteSlice) String() string { return fmt.Sprint(s.ToSlice()) }
@@ -0,0 +1,124 @@
+package segmentedSlice
+
+// View is a read-only view of a Slice that applies a function to the elements as they are read,
+// without materializing a new slice. See MapView.
+type View struct {
+	ss *Slice
+	fn func(v interface{}) interface{}
+}
+
+// MapView returns a View that applies fn on every Get, ForEach and Iter, fn is called every time an element is read.
+// The view reflects any later change to ss.
+// Example:
+// 	names := users.MapView(func(v interface{}) interface{} { return v.(*User).Name })
+func (ss *Slice) MapView(fn func(v interface{}) interface{}) *View {
+	return &View{ss: ss, fn: fn}
+}
+
+// MapView returns a View that applies fn on top of v's function.
+func (v *View) MapView(fn func(v interface{}) interface{}) *View {
+	inner := v.fn
+	return &View{ss: v.ss, fn: func(e interface{}) interface{} { return fn(inner(e)) }}
+}
+
+// Len returns the number of elements in the view.
+func (v *View) Len() int { return v.ss.Len() }
+
+// Get returns the mapped element at the specified index, it panics if i is out of range.
+func (v *View) Get(i int) interface{} { return v.fn(v.ss.Get(i)) }
+
+// ForEach calls fn for each mapped element.
+// If fn returns true, it breaks early and returns true otherwise returns false.
+func (v *View) ForEach(fn func(i int, v interface{}) (breakNow bool)) bool {
+	return v.ss.ForEach(func(i int, e interface{}) bool { return fn(i, v.fn(e)) })
+}
+
+// Iter returns an iterator over the mapped elements.
+func (v *View) Iter() *ViewIterator {
+	return &ViewIterator{it: NewIterVal(v.ss, 0, v.ss.Len()), fn: v.fn}
+}
+
+// Materialize returns a new Slice holding the mapped elements.
+func (v *View) Materialize() *Slice { return v.ss.Map(v.fn) }
+
+// ViewIterator is an iterator over a View.
+type ViewIterator struct {
+	it Iterator
+	fn func(v interface{}) interface{}
+}
+
+// More returns true if the iterator have more items.
+func (vi *ViewIterator) More() bool { return vi.it.More() }
+
+// Len returns the number of items left in the iterator.
+func (vi *ViewIterator) Len() int { return vi.it.Len() }
+
+// Next returns the next item.
+func (vi *ViewIterator) Next() interface{} { return vi.fn(vi.it.Next()) }
+
+// NextIndex returns the next item and index.
+func (vi *ViewIterator) NextIndex() (idx int, val interface{}) {
+	idx, val = vi.it.NextIndex()
+	return idx, vi.fn(val)
+}
+
+// FilteredView is a read-only view of a Slice that skips the elements that don't match a predicate while iterating,
+// without allocating a filtered copy. See FilterView.
+type FilteredView struct {
+	ss *Slice
+	fn func(v interface{}) bool
+}
+
+// FilterView returns a FilteredView that only yields the elements fn returns true for,
+// fn is called every time an element is visited. The view reflects any later change to ss.
+func (ss *Slice) FilterView(fn func(v interface{}) bool) *FilteredView {
+	return &FilteredView{ss: ss, fn: fn}
+}
+
+// ForEach calls fn for each matching element, i is the element's index in the underlying slice.
+// If fn returns true, it breaks early and returns true otherwise returns false.
+func (fv *FilteredView) ForEach(fn func(i int, v interface{}) (breakNow bool)) bool {
+	return fv.ss.ForEach(func(i int, v interface{}) bool { return fv.fn(v) && fn(i, v) })
+}
+
+// Count returns the number of matching elements, it visits the whole slice.
+func (fv *FilteredView) Count() int { return fv.ss.CountFunc(fv.fn) }
+
+// Iter returns an iterator over the matching elements.
+func (fv *FilteredView) Iter() *FilterIterator {
+	return &FilterIterator{it: NewIterVal(fv.ss, 0, fv.ss.Len()), fn: fv.fn}
+}
+
+// Materialize returns a new Slice holding the matching elements, the same as Filter.
+func (fv *FilteredView) Materialize() *Slice { return fv.ss.Filter(fv.fn) }
+
+// FilterIterator is an iterator over a FilteredView.
+type FilterIterator struct {
+	it Iterator
+	fn func(v interface{}) bool
+}
+
+// More returns true if the iterator have more matching items, it skips the items that don't match.
+func (fi *FilterIterator) More() bool {
+	for fi.it.More() {
+		if fi.fn(fi.it.Peek()) {
+			return true
+		}
+		fi.it.start++
+	}
+	return false
+}
+
+// Next returns the next matching item.
+func (fi *FilterIterator) Next() (val interface{}) {
+	_, val = fi.NextIndex()
+	return
+}
+
+// NextIndex returns the next matching item and its index in the underlying slice.
+func (fi *FilterIterator) NextIndex() (idx int, val interface{}) {
+	if !fi.More() {
+		panic("no more items")
+	}
+	return fi.it.NextIndex()
+}